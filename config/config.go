@@ -0,0 +1,183 @@
+// Package config loads the logger and recovery middlewares configuration from
+// a YAML or JSON document, so the logging policy can be managed as config.
+//
+// Example:
+//
+//	logger:
+//	  min-level: INFO
+//	  status-levels:
+//	    - {min: 200, max: 399, level: DEBUG}
+//	    - {min: 400, max: 599, level: WARN}
+//	  blacklist-paths: ["^/health$"]
+//	  fields: [status, method, path, latency]
+//	  sample-rate: 0.5
+//	  redaction:
+//	    query-keys: ["(?i)^token$"]
+//	recovery:
+//	  request: false
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+
+	"github.com/FabienMht/ginslog/logger"
+	"github.com/FabienMht/ginslog/recovery"
+	"github.com/FabienMht/ginslog/redact"
+	"gopkg.in/yaml.v3"
+)
+
+// File represents the configuration document.
+type File struct {
+	Logger   Logger   `yaml:"logger"`
+	Recovery Recovery `yaml:"recovery"`
+}
+
+// Logger represents the logger middleware configuration, see logger.Settings.
+// The unset fields keep the middleware defaults.
+type Logger struct {
+	logger.Settings `yaml:",inline"`
+
+	// Redaction rules of the headers, the paths and the referers.
+	Redaction *Redaction `yaml:"redaction"`
+}
+
+// Recovery represents the recovery middleware configuration.
+// The unset fields keep the middleware defaults.
+type Recovery struct {
+	// Log level of the recovered panics.
+	Level *slog.Level `yaml:"level"`
+	// Log the error from the panic.
+	Error *bool `yaml:"error"`
+	// Log the HTTP request.
+	Request *bool `yaml:"request"`
+	// Log the stack trace.
+	Stack *bool `yaml:"stack"`
+	// Redaction rules of the HTTP request dump.
+	Redaction *Redaction `yaml:"redaction"`
+}
+
+// Redaction represents the redaction rules, see the redact package.
+type Redaction struct {
+	// Replace the default redacted headers.
+	WithoutDefaultHeaders bool `yaml:"without-default-headers"`
+	// Names of the redacted headers.
+	Headers []string `yaml:"headers"`
+	// Regexes of the redacted headers.
+	HeaderPatterns []string `yaml:"header-patterns"`
+	// Regexes of the redacted query parameters.
+	QueryKeys []string `yaml:"query-keys"`
+	// Regexes of the redacted values.
+	ValuePatterns []string `yaml:"value-patterns"`
+}
+
+// Load reads and parses the YAML or JSON configuration file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses the YAML or JSON configuration document.
+// It returns an error if a key is unknown.
+func Parse(data []byte) (*File, error) {
+	f := &File{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return f, nil
+}
+
+// LoggerOptions returns the logger middleware options.
+// It returns an error if the configuration is invalid.
+func (f *File) LoggerOptions() ([]logger.ConfigOption, error) {
+	c := f.Logger
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	opts := c.Options()
+
+	if c.Redaction != nil {
+		redactor, err := c.Redaction.Redactor()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, logger.WithRedactor(redactor))
+	}
+
+	return opts, nil
+}
+
+// RecoveryOptions returns the recovery middleware options.
+// It returns an error if the configuration is invalid.
+func (f *File) RecoveryOptions() ([]recovery.ConfigOption, error) {
+	c := f.Recovery
+	opts := []recovery.ConfigOption{}
+
+	if c.Level != nil {
+		opts = append(opts, recovery.WithDefaultLevel(*c.Level))
+	}
+	if c.Error != nil && !*c.Error {
+		opts = append(opts, recovery.WithoutError())
+	}
+	if c.Request != nil && !*c.Request {
+		opts = append(opts, recovery.WithoutRequest())
+	}
+	if c.Stack != nil && !*c.Stack {
+		opts = append(opts, recovery.WithoutStack())
+	}
+
+	if c.Redaction != nil {
+		redactor, err := c.Redaction.Redactor()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, recovery.WithRedactor(redactor))
+	}
+
+	return opts, nil
+}
+
+// Redactor returns the redact.Redactor of the redaction rules.
+// It returns an error if a regex is invalid.
+func (r *Redaction) Redactor() (*redact.Redactor, error) {
+	for key, exprs := range map[string][]string{
+		"header-patterns": r.HeaderPatterns,
+		"query-keys":      r.QueryKeys,
+		"value-patterns":  r.ValuePatterns,
+	} {
+		if err := checkRegexps(key, exprs); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := []redact.Option{}
+	if r.WithoutDefaultHeaders {
+		opts = append(opts, redact.WithoutDefaultHeaders())
+	}
+	return redact.New(append(opts,
+		redact.WithHeaders(r.Headers),
+		redact.WithHeaderPatterns(r.HeaderPatterns),
+		redact.WithQueryKeys(r.QueryKeys),
+		redact.WithValuePatterns(r.ValuePatterns),
+	)...), nil
+}
+
+// checkRegexps checks if the regexes are valid.
+func checkRegexps(key string, exprs []string) error {
+	for _, expr := range exprs {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FabienMht/ginslog/logger"
+	"github.com/FabienMht/ginslog/slogtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	debug := slog.LevelDebug
+	request := false
+	rate := 0.5
+
+	tests := []struct {
+		name    string
+		data    string
+		want    *File
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			data: "",
+			want: &File{},
+		},
+		{
+			name: "yaml",
+			data: `
+logger:
+  min-level: debug
+  status-levels:
+    - {min: 200, max: 399, level: DEBUG}
+  blacklist-paths: ["^/health$"]
+  fields: [status, path]
+  sample-rate: 0.5
+recovery:
+  request: false
+  redaction:
+    query-keys: ["(?i)^token$"]
+`,
+			want: &File{
+				Logger: Logger{Settings: logger.Settings{
+					MinLevel:       &debug,
+					StatusLevels:   []logger.StatusLevel{{Min: 200, Max: 399, Level: slog.LevelDebug}},
+					BlacklistPaths: []string{"^/health$"},
+					Fields:         []logger.Field{logger.FieldStatus, logger.FieldPath},
+					SampleRate:     &rate,
+				}},
+				Recovery: Recovery{
+					Request:   &request,
+					Redaction: &Redaction{QueryKeys: []string{"(?i)^token$"}},
+				},
+			},
+		},
+		{
+			name: "json",
+			data: `{"logger": {"min-level": "DEBUG", "fields": ["status", "path"]}, "recovery": {"request": false}}`,
+			want: &File{
+				Logger:   Logger{Settings: logger.Settings{MinLevel: &debug, Fields: []logger.Field{logger.FieldStatus, logger.FieldPath}}},
+				Recovery: Recovery{Request: &request},
+			},
+		},
+		{
+			name:    "unknown key",
+			data:    "logger:\n  level: INFO\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid level",
+			data:    "logger:\n  min-level: VERBOSE\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse([]byte(tt.data))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, f)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ginslog.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("logger:\n  blacklist-paths: [\"^/health$\"]\n"), 0o600))
+
+	f, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"^/health$"}, f.Logger.BlacklistPaths)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestLoggerOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		path        string
+		wantErr     bool
+		wantLevel   slog.Level
+		wantFields  []slog.Attr
+		wantRecords int
+	}{
+		{
+			name:        "default",
+			data:        "logger:\n  fields: [status, path]\n",
+			path:        "/test",
+			wantLevel:   slog.LevelInfo,
+			wantFields:  []slog.Attr{slog.Int("status", 200), slog.String("path", "/test")},
+			wantRecords: 1,
+		},
+		{
+			name: "levels and redaction",
+			data: `
+logger:
+  fields: [status, path]
+  status-levels: [{min: 200, max: 299, level: DEBUG}]
+  redaction:
+    value-patterns: ["secret"]
+`,
+			path:        "/test/secret",
+			wantLevel:   slog.LevelDebug,
+			wantFields:  []slog.Attr{slog.Int("status", 200), slog.String("path", "/test/[REDACTED]")},
+			wantRecords: 1,
+		},
+		{
+			name:        "min level",
+			data:        "logger:\n  min-level: WARN\n",
+			path:        "/test",
+			wantRecords: 0,
+		},
+		{
+			name:        "blacklist",
+			data:        "logger:\n  blacklist-paths: [\"^/test\"]\n",
+			path:        "/test",
+			wantRecords: 0,
+		},
+		{
+			name:    "whitelist and blacklist",
+			data:    "logger:\n  whitelist-paths: [\"^/test\"]\n  blacklist-paths: [\"^/health\"]\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid regex",
+			data:    "logger:\n  blacklist-paths: [\"(\"]\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid user agent regex",
+			data:    "logger:\n  blacklist-user-agents: [\"(\"]\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid field",
+			data:    "logger:\n  fields: [status, host]\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid sample rate",
+			data:    "logger:\n  sample-rate: 2\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid status range",
+			data:    "logger:\n  status-levels: [{min: 500, max: 400, level: ERROR}]\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid redaction",
+			data:    "logger:\n  redaction:\n    query-keys: [\"(\"]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse([]byte(tt.data))
+			require.NoError(t, err)
+
+			opts, err := f.LoggerOptions()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}),
+				t,
+				tt.wantLevel,
+				tt.wantFields,
+				nil,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(logger.New(slog.New(handler), opts...))
+
+			// Define routes
+			router.GET("/*path", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tt.path, nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, tt.wantRecords, handler.Records())
+		})
+	}
+}
+
+func TestRecoveryOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name:    "default",
+			data:    "",
+			wantLen: 0,
+		},
+		{
+			name:    "fields and redaction",
+			data:    "recovery:\n  level: WARN\n  error: true\n  request: false\n  stack: false\n  redaction:\n    headers: [X-Token]\n",
+			wantLen: 4,
+		},
+		{
+			name:    "invalid redaction",
+			data:    "recovery:\n  redaction:\n    header-patterns: [\"(\"]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse([]byte(tt.data))
+			require.NoError(t, err)
+
+			opts, err := f.RecoveryOptions()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, opts, tt.wantLen)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// Package ginslog provides Gin middlewares for slog logging: the logger,
+// recovery and requestid packages, and Default bundling them.
+package ginslog
+
+import (
+	"log/slog"
+
+	"github.com/FabienMht/ginslog/logger"
+	"github.com/FabienMht/ginslog/recovery"
+	"github.com/FabienMht/ginslog/redact"
+	"github.com/FabienMht/ginslog/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// Default returns the request ID, logging and recovery middlewares, in this
+// order, as a replacement of gin.Logger and gin.Recovery:
+//
+//	r.Use(ginslog.Default(logger)...)
+//
+// The request ID is set before the other middlewares so they share it, and
+// the logged headers and the dumped requests are redacted with the same
+// redact.New policy.
+func Default(l *slog.Logger) []gin.HandlerFunc {
+	redactor := redact.New()
+	return []gin.HandlerFunc{
+		requestid.New(),
+		logger.New(l, logger.WithRedactor(redactor)),
+		recovery.New(l, recovery.WithRedactor(redactor)),
+	}
+}
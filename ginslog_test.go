@@ -0,0 +1,80 @@
+package ginslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FabienMht/ginslog/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantMessages []string
+	}{
+		{
+			name:         "request",
+			path:         "/test",
+			wantStatus:   http.StatusOK,
+			wantMessages: []string{"Incoming request"},
+		},
+		{
+			name:         "panic",
+			path:         "/panic",
+			wantStatus:   http.StatusInternalServerError,
+			wantMessages: []string{"Panic recovered", "Incoming request"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger writing JSON lines
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(Default(logger)...)
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.String(http.StatusOK, requestid.Get(c))
+			})
+			router.GET("/panic", func(c *gin.Context) {
+				panic("Panic!")
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tt.path, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer secret")
+			router.ServeHTTP(resp, req)
+			require.Equal(t, tt.wantStatus, resp.Code)
+
+			// Check the log lines
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, len(tt.wantMessages))
+			var record map[string]any
+			for i, line := range lines {
+				record = map[string]any{}
+				require.NoError(t, json.Unmarshal([]byte(line), &record))
+				require.Equal(t, tt.wantMessages[i], record["msg"])
+				require.NotContains(t, line, "secret")
+			}
+
+			// Check the request ID is shared
+			requestID := resp.Header().Get(requestid.HeaderRequestID)
+			require.NotEmpty(t, requestID)
+			require.Equal(t, requestID, record["request-id"])
+		})
+	}
+}
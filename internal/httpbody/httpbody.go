@@ -0,0 +1,91 @@
+// Package httpbody provides the HTTP bodies helpers shared by the logger and
+// recovery middlewares.
+package httpbody
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"strings"
+)
+
+// TruncatedSuffix is appended to the truncated bodies.
+const TruncatedSuffix = "...(truncated)"
+
+// readCloser combines a reader and a closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Peek reads up to maxSize bytes of the body and returns them with a body
+// replaying them before the remaining bytes. It also reports if the body is
+// larger than maxSize.
+func Peek(body io.ReadCloser, maxSize int) ([]byte, io.ReadCloser, bool, error) {
+	// Read one more byte to know if the body is truncated
+	buf, err := io.ReadAll(io.LimitReader(body, int64(maxSize)+1))
+	replay := &readCloser{Reader: io.MultiReader(bytes.NewReader(buf), body), Closer: body}
+	if err != nil {
+		return nil, replay, false, err
+	}
+	if len(buf) > maxSize {
+		return buf[:maxSize], replay, true, nil
+	}
+	return buf, replay, false, nil
+}
+
+// Format returns the body as a string, marking it if truncated.
+func Format(body []byte, truncated bool) string {
+	if truncated {
+		return string(body) + TruncatedSuffix
+	}
+	return string(body)
+}
+
+// binaryMediaTypes are the media types not logged as raw bytes.
+// The media types can end with a wildcard.
+var binaryMediaTypes = []string{
+	"multipart/*",
+	"application/octet-stream",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-tar",
+	"application/pdf",
+	"image/*",
+	"audio/*",
+	"video/*",
+	"font/*",
+}
+
+// IsBinary checks if a body is binary, multipart or compressed from its
+// Content-Type and Content-Encoding headers.
+func IsBinary(contentType, contentEncoding string) bool {
+	if contentEncoding != "" && contentEncoding != "identity" {
+		return true
+	}
+	return contentType != "" && MatchContentType(contentType, binaryMediaTypes)
+}
+
+// MatchContentType checks if a Content-Type header matches one of the allowed
+// media types. The allowed media types can end with a wildcard (e.g. text/*).
+// All the content types match if the allowlist is empty.
+func MatchContentType(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, v := range allowed {
+		if prefix, ok := strings.CutSuffix(v, "*"); ok && strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+		if mediaType == v {
+			return true
+		}
+	}
+	return false
+}
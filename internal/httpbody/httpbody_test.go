@@ -0,0 +1,66 @@
+package httpbody
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeek(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		maxSize       int
+		wantBody      string
+		wantTruncated bool
+	}{
+		{
+			name:     "smaller body",
+			body:     "test",
+			maxSize:  1024,
+			wantBody: "test",
+		},
+		{
+			name:          "larger body",
+			body:          "test body",
+			maxSize:       4,
+			wantBody:      "test",
+			wantTruncated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, replay, truncated, err := Peek(io.NopCloser(strings.NewReader(tt.body)), tt.maxSize)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBody, string(body))
+			require.Equal(t, tt.wantTruncated, truncated)
+
+			// Check the whole body is replayed
+			replayed, err := io.ReadAll(replay)
+			require.NoError(t, err)
+			require.Equal(t, tt.body, string(replayed))
+		})
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentType     string
+		contentEncoding string
+		want            bool
+	}{
+		{name: "json", contentType: "application/json", want: false},
+		{name: "no content type", contentType: "", want: false},
+		{name: "multipart", contentType: "multipart/form-data; boundary=test", want: true},
+		{name: "image", contentType: "image/png", want: true},
+		{name: "compressed", contentType: "application/json", contentEncoding: "gzip", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, IsBinary(tt.contentType, tt.contentEncoding))
+		})
+	}
+}
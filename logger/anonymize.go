@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// IPAnonymizer allows to anonymize the client IP addresses before logging them.
+type IPAnonymizer func(ip string) string
+
+// TruncateIP returns an IPAnonymizer keeping only the first ipv4Bits bits of
+// the IPv4 addresses and the first ipv6Bits bits of the IPv6 addresses
+// (e.g. 24 and 48), the other bits are set to zero.
+// Invalid IP addresses are not logged.
+func TruncateIP(ipv4Bits, ipv6Bits int) IPAnonymizer {
+	return func(ip string) string {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return ""
+		}
+		addr = addr.Unmap()
+
+		bits := ipv6Bits
+		if addr.Is4() {
+			bits = ipv4Bits
+		}
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			return ""
+		}
+		return prefix.Addr().String()
+	}
+}
+
+// HashIP returns an IPAnonymizer replacing the IP addresses with their
+// HMAC-SHA256 using the given secret key. The same IP address always gives
+// the same hash, which allows per-client analytics without storing the
+// IP addresses.
+func HashIP(key []byte) IPAnonymizer {
+	return func(ip string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(ip))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+}
+
+// anonymizeIP anonymizes an IP address if an anonymizer is set.
+func (c *Config) anonymizeIP(ip string) string {
+	if c.ipAnonymizer == nil {
+		return ip
+	}
+	return c.ipAnonymizer(ip)
+}
+
+// anonymizeAddr anonymizes the IP address of a host:port address.
+func (c *Config) anonymizeAddr(addr string) string {
+	if c.ipAnonymizer == nil {
+		return addr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return c.ipAnonymizer(addr)
+	}
+	return net.JoinHostPort(c.ipAnonymizer(host), port)
+}
+
+// anonymizeIPList anonymizes a comma separated list of IP addresses.
+func (c *Config) anonymizeIPList(list string) string {
+	if c.ipAnonymizer == nil {
+		return list
+	}
+	ips := strings.Split(list, ",")
+	for i, ip := range ips {
+		ips[i] = c.ipAnonymizer(strings.TrimSpace(ip))
+	}
+	return strings.Join(ips, ", ")
+}
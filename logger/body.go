@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"log/slog"
+)
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.ReadCloser
+
+	// Number of bytes read.
+	n int64
+}
+
+// Read implements io.Reader.
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// digestReader computes the SHA-256 digest of a request body.
+type digestReader struct {
+	io.ReadCloser
+
+	// SHA-256 hash of the bytes read.
+	hash hash.Hash
+	// Number of bytes read.
+	n int64
+}
+
+// newDigestReader returns a new digestReader.
+func newDigestReader(body io.ReadCloser) *digestReader {
+	return &digestReader{ReadCloser: body, hash: sha256.New()}
+}
+
+// Read implements io.Reader.
+func (r *digestReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	r.n += int64(n)
+	return n, err
+}
+
+// digest reads the remaining bytes not read by the handlers, up to maxSize
+// bytes in total, and returns the digest of the body. The digest is marked as
+// partial if bytes remain or if they can't be checked. It returns false if the
+// body can't be read.
+func (r *digestReader) digest(maxSize int64) (slog.Attr, bool) {
+	if _, err := io.Copy(io.Discard, io.LimitReader(r, max(maxSize-r.n, 0))); err != nil {
+		return slog.Attr{}, false
+	}
+
+	attrs := []any{
+		slog.String("sha256", hex.EncodeToString(r.hash.Sum(nil))),
+		slog.Int64("size", r.n),
+	}
+
+	// Check if bytes remain without hashing them, the digest is unreliable
+	// if the body can't be read
+	if n, err := io.ReadFull(r.ReadCloser, make([]byte, 1)); n > 0 || (err != nil && !errors.Is(err, io.EOF)) {
+		attrs = append(attrs, slog.Bool("partial", true))
+	}
+	return slog.Group("request-digest", attrs...), true
+}
+
+// binaryBodyGroup returns a group with the content type and the size
+// of a binary body instead of its raw bytes.
+func binaryBodyGroup(key, contentType string, size int64) slog.Attr {
+	return slog.Group(key,
+		slog.String("content-type", contentType),
+		slog.Int64("size", size),
+	)
+}
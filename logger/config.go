@@ -1,10 +1,20 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"math/rand"
+	"net/http"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/FabienMht/ginslog/redact"
+	"github.com/FabienMht/ginslog/requestid"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,65 +27,278 @@ const (
 	HTTPServerErrorRegex   = "^5[0-9]{2}$"
 )
 
+// HeaderCorrelationID is the common HTTP header carrying the correlation ID.
+const HeaderCorrelationID = "X-Correlation-ID"
+
+// DefaultMessage is the default message of the log line.
+const DefaultMessage = "Incoming request"
+
+// healthCheckPaths are the common probe paths, see WithSkipHealthChecks.
+var healthCheckPaths = map[string]struct{}{
+	"/healthz": {},
+	"/livez":   {},
+	"/readyz":  {},
+	"/ping":    {},
+	"/metrics": {},
+}
+
 // CustomFields allows to add custom fields to the log line.
 type CustomFields func(c *gin.Context) []slog.Attr
 
-// CustomLogger allows to call a custom logger function.
-type CustomLogger func(c *gin.Context, logger *slog.Logger)
+// CustomLogger is a callback called after the log line is emitted, with the
+// logger used to emit it and the level, the message and the fields of the log
+// line, so they don't have to be computed again. It is optional.
+type CustomLogger func(c *gin.Context, logger *slog.Logger, level slog.Level, message string, attrs []slog.Attr)
+
+// Record is a pending log line, see BeforeLog.
+type Record struct {
+	Level   slog.Level
+	Message string
+	Attrs   []slog.Attr
+}
+
+// BeforeLog is a hook called before the log line is emitted. It can modify the
+// Record and returns false to cancel the log line.
+type BeforeLog func(c *gin.Context, record *Record) bool
+
+// OnLog is a hook called after the log line is emitted with its level and its
+// fields (e.g. to increment metrics or annotate spans).
+type OnLog func(c *gin.Context, level slog.Level, attrs []slog.Attr)
+
+// UserExtractor allows to extract the authenticated user from the request.
+// Return an empty string if the user is unknown.
+type UserExtractor func(c *gin.Context) string
+
+// UserFromBasicAuth extracts the user from the basic auth username.
+func UserFromBasicAuth(c *gin.Context) string {
+	if user := c.GetString(gin.AuthUserKey); user != "" {
+		return user
+	}
+	user, _, _ := c.Request.BasicAuth()
+	return user
+}
+
+// UserFromContextKey returns a UserExtractor that extracts the user from
+// a gin context key set by an authentication middleware.
+func UserFromContextKey(key string) UserExtractor {
+	return func(c *gin.Context) string {
+		return c.GetString(key)
+	}
+}
+
+// IPEnricher allows to add fields based on the client IP address
+// (e.g. country or ASN from a GeoIP database).
+type IPEnricher func(ip string) []slog.Attr
 
 // CustomFilter allows to filter the log line.
 // Return true to log the line, false otherwise.
 type CustomFilter func(c *gin.Context) bool
 
-// httpLevel associates a log level to an HTTP return code regex.
+// AttrHook allows to drop, rewrite or enrich the fields of the log line
+// just before it is emitted.
+type AttrHook func(c *gin.Context, attrs []slog.Attr) []slog.Attr
+
+// MessageFunc allows to build the message of the log line.
+type MessageFunc func(c *gin.Context) string
+
+// LevelFunc allows to compute the log level of the request.
+type LevelFunc func(c *gin.Context) slog.Level
+
+// Skipper allows to skip the log line.
+// Return true to skip the line, false otherwise.
+type Skipper func(c *gin.Context) bool
+
+// StatusRange associates a log level to an inclusive range of HTTP return codes.
+// The level can be a slog.LevelVar to change it at runtime.
+type StatusRange struct {
+	Min   int
+	Max   int
+	Level slog.Leveler
+}
+
+// httpLevel associates a log level to an HTTP return code regex or range.
 type httpLevel struct {
 	// Log level to use.
-	level slog.Level
-	// Compiled regex.
+	level slog.Leveler
+	// Compiled regex, nil for a range.
 	regexp *regexp.Regexp
+	// Inclusive range of HTTP return codes.
+	min int
+	max int
 }
 
 // NewhttpLevel returns a new httpLevel.
-func newhttpLevel(expr string, level slog.Level) *httpLevel {
+func newhttpLevel(expr string, level slog.Leveler) *httpLevel {
 	return &httpLevel{
 		level:  level,
 		regexp: regexp.MustCompile(expr),
 	}
 }
 
-// Match returns true if the HTTP return code matches the regex.
+// newhttpRangeLevel returns a new httpLevel matching a range of HTTP return codes.
+func newhttpRangeLevel(from, to int, level slog.Leveler) *httpLevel {
+	return &httpLevel{
+		level: level,
+		min:   from,
+		max:   to,
+	}
+}
+
+// Match returns true if the HTTP return code matches the regex or the range.
 func (h *httpLevel) match(code int) bool {
+	if h.regexp == nil {
+		return code >= h.min && code <= h.max
+	}
 	return h.regexp.MatchString(fmt.Sprintf("%d", code))
 }
 
+// pathLevel associates a log level to a path regex.
+type pathLevel struct {
+	// Log level to use.
+	level slog.Level
+	// Compiled regex.
+	regexp *regexp.Regexp
+}
+
+// slowLevel associates a log level to a latency threshold.
+type slowLevel struct {
+	// Log level to use.
+	level slog.Level
+	// Latency threshold.
+	threshold time.Duration
+}
+
+// contextAttr associates a request context key to a field name.
+type contextAttr struct {
+	// Request context key.
+	key any
+	// Field name.
+	name string
+}
+
+// headerFilter includes or excludes the requests based on a header value.
+type headerFilter struct {
+	// Canonical header name.
+	header string
+	// Compiled regex matching the header value.
+	regexp *regexp.Regexp
+	// Log only the matching requests if true, skip them otherwise.
+	include bool
+}
+
 // Config represents the logging middleware configuration.
 type Config struct {
 	// Default log level.
-	defaultLevel slog.Level
+	defaultLevel slog.Leveler
 
 	// Log level based on the HTTP return code.
 	// Regex can be used to match multiple codes.
 	httpLevels []*httpLevel
 
+	// Function to compute the log level, it overrides the other levels.
+	levelFunc LevelFunc
+
+	// Log level based on the path, evaluated before the HTTP return code.
+	pathLevels []*pathLevel
+
+	// Log level based on the HTTP method, for the requests logged below WARN.
+	methodLevels map[string]slog.Level
+
+	// Minimum log level of the slow requests.
+	slowLevels []*slowLevel
+
 	// Whitelist or blacklist paths.
 	// By default, all paths are logged.
 	// If a whitelist is set, only whitelisted paths are logged.
 	// If a blacklist is set, all paths except blacklisted are logged.
-	whitelistPaths []*regexp.Regexp
-	blacklistPaths []*regexp.Regexp
+	whitelistPaths []pathMatcher
+	blacklistPaths []pathMatcher
+
+	// Blacklist user agents regexes.
+	blacklistUserAgents []*regexp.Regexp
+
+	// Filters based on the request headers values.
+	headerFilters []*headerFilter
+
+	// Sampling rates by HTTP return code class (2 for 2XX, ...).
+	// The classes without rate are all logged.
+	sampleRates map[int]float64
+	// Sampler of the successful requests targeting a maximum log rate.
+	adaptiveSampler *adaptiveSampler
+	// Limiter of the log lines per client IP address.
+	clientLimiter *clientLimiter
+	// Suppressor of the identical log lines.
+	deduplicator *deduplicator
+	// Aggregator of the requests per route.
+	summarizer *summarizer
+
+	// Normalize the paths before filtering and logging.
+	normalizePaths bool
+	lowercasePaths bool
 
 	// Custom filter function.
 	customFilter CustomFilter
 
-	// Custom logger function.
+	// Skipper functions evaluated after the handlers.
+	skippers []Skipper
+	// Skip the common probe paths and the Kubernetes probes.
+	skipHealthChecks bool
+
+	// Always log requests with a gin bind (parse) error,
+	// even if they are filtered out.
+	malformedRequests bool
+
+	// Callback called after the log line is emitted, nil if not set.
 	customLogger CustomLogger
 
+	// Hooks called before the log line is emitted.
+	beforeLog []BeforeLog
+
+	// Hooks called after the log line is emitted.
+	onLog []OnLog
+
+	// Minimum level of the logged requests, nil to log all the levels.
+	minLevel slog.Leveler
+
+	// Logging configuration changed at runtime.
+	control *Control
+
+	// Logger used for the server errors.
+	errorLogger *slog.Logger
+
+	// Pass the request context to the handler instead of context.Background.
+	requestContext bool
+
 	// Custom function to add custom fields to the log line.
 	customFields CustomFields
 
+	// Constant fields added to every log line.
+	staticAttrs []slog.Attr
+
+	// Function to build the message of the log line.
+	messageFunc MessageFunc
+
+	// Log a line when the request starts, at the given level.
+	requestStartLog   bool
+	requestStartLevel slog.Level
+
+	// Log the WebSocket and Server-Sent Events connections when opened and
+	// closed instead of as a single request.
+	longLivedConnections bool
+
+	// Function called with the final fields before logging.
+	attrHook AttrHook
+
+	// Fields logged only when the HTTP return code is greater than or equal
+	// to the errors only status.
+	errorsOnlyFields map[string]struct{}
+	errorsOnlyStatus int
+
 	// Default fields to log.
 	// Client IP address.
 	ipField bool
+	// Function to anonymize the client IP addresses.
+	ipAnonymizer IPAnonymizer
 	// HTTP return code.
 	statusField bool
 	// HTTP method.
@@ -84,10 +307,122 @@ type Config struct {
 	pathField bool
 	// User agent.
 	userAgentField bool
+	// Parse the user agent instead of logging the raw string.
+	parseUserAgent bool
 	// Request latency.
 	latencyField bool
+	// Log the latency as a human readable string.
+	prettyLatency bool
+	// Request body size.
+	bytesInField bool
+	// Count the request body bytes read instead of using the Content-Length.
+	bytesInRead bool
+	// Response body size.
+	bytesOutField bool
 	// UUID generated X-Request-ID header.
 	requestIDField bool
+	// Group the default fields under the client and http groups.
+	groups bool
+	// Names of the default fields, the default names are used if missing.
+	fieldNames map[Field]string
+	// Add the OpenTelemetry http.route and network.peer.address fields.
+	otelFields bool
+
+	// Optional fields to log.
+	// Gin route parameters.
+	routeParamsField bool
+	// HTTP host.
+	hostField bool
+	// HTTP referer.
+	refererField bool
+	// HTTP protocol version.
+	protoField bool
+	// TLS connection state.
+	tlsField bool
+	// Request start time.
+	startTimeField bool
+	// Layout of the request start time, slog formats it if empty.
+	startTimeLayout string
+	// Convert the request start time to UTC.
+	startTimeUTC bool
+	// Request headers.
+	requestHeaders []string
+	// Response headers.
+	responseHeaders []string
+	// Response content type.
+	contentTypeField bool
+	// Gin context errors.
+	errorsField bool
+	// Function to extract the authenticated user.
+	userExtractor UserExtractor
+	// Client remote address (IP and port).
+	remoteAddrField bool
+	// Full X-Forwarded-For chain.
+	forwardedForField bool
+	// Gin handler name.
+	handlerField bool
+	// Request cookies names.
+	cookiesField bool
+	// Time to first byte.
+	ttfbField bool
+	// Maximum size of the request body to log, zero to not log the body.
+	requestBodyMaxSize int
+	// Content types of the request body to log, all if empty.
+	requestBodyContentTypes []string
+	// Maximum size of the response body to log, zero to not log the body.
+	responseBodyMaxSize int
+	// Content types of the response body to log, all if empty.
+	responseBodyContentTypes []string
+	// Maximum size of the request body to digest, zero to not digest the body.
+	requestDigestMaxSize int
+	// HTTP return codes for which the bodies are logged, all if empty.
+	bodyStatuses []*regexp.Regexp
+	// Function to add fields based on the client IP address.
+	ipEnricher IPEnricher
+
+	// Redaction policy of the headers values, the path and the referer.
+	redactor *redact.Redactor
+	// Sensitive headers logged without redaction.
+	unredactedHeaders map[string]struct{}
+
+	// Log the client disconnects in the aborted field.
+	abortedField bool
+	// HTTP return code logged for the client disconnects, unchanged if 0.
+	abortedStatus int
+
+	// Request context deadline outcome.
+	deadlineField bool
+
+	// HTTP header carrying the correlation ID.
+	// The correlation ID is not logged if empty.
+	correlationIDHeader string
+
+	// Request context values to log.
+	contextAttrs []*contextAttr
+
+	// Request ID options used when the requestid middleware is not used.
+	requestIDOptions []requestid.ConfigOption
+
+	// Options used to build the Config, replayed by Clone.
+	options []ConfigOption
+}
+
+// NewConfig returns a new Config built with the given options. It can be
+// derived with Clone to mount middlewares with different options on the
+// route groups, see NewFromConfig.
+func NewConfig(opts ...ConfigOption) *Config {
+	config := newConfig()
+	for _, opt := range opts {
+		opt(config)
+	}
+	config.options = opts
+	return config
+}
+
+// Clone returns a copy of the Config with the given options applied on top
+// of the options of the Config (e.g. to log more fields on the /admin routes).
+func (c *Config) Clone(opts ...ConfigOption) *Config {
+	return NewConfig(append(slices.Clone(c.options), opts...)...)
 }
 
 // newConfig returns a new Config.
@@ -95,24 +430,96 @@ func newConfig() *Config {
 	return &Config{
 		defaultLevel: slog.LevelInfo,
 		httpLevels: []*httpLevel{
-			newhttpLevel(HTTPInformationalRegex, slog.LevelInfo),
-			newhttpLevel(HTTPSuccessfulRegex, slog.LevelInfo),
-			newhttpLevel(HTTPRedirectionRegex, slog.LevelInfo),
-			newhttpLevel(HTTPClientErrorRegex, slog.LevelWarn),
-			newhttpLevel(HTTPServerErrorRegex, slog.LevelError),
+			newhttpRangeLevel(100, 199, slog.LevelInfo),
+			newhttpRangeLevel(200, 299, slog.LevelInfo),
+			newhttpRangeLevel(300, 399, slog.LevelInfo),
+			newhttpRangeLevel(400, 499, slog.LevelWarn),
+			newhttpRangeLevel(500, 599, slog.LevelError),
 		},
-		whitelistPaths: []*regexp.Regexp{},
-		blacklistPaths: []*regexp.Regexp{},
-		customFilter:   nil,
-		customLogger:   nil,
-		customFields:   nil,
-		ipField:        true,
-		statusField:    true,
-		methodField:    true,
-		pathField:      true,
-		userAgentField: true,
-		latencyField:   true,
-		requestIDField: true,
+		levelFunc:                nil,
+		pathLevels:               []*pathLevel{},
+		methodLevels:             map[string]slog.Level{},
+		slowLevels:               []*slowLevel{},
+		whitelistPaths:           []pathMatcher{},
+		blacklistPaths:           []pathMatcher{},
+		blacklistUserAgents:      []*regexp.Regexp{},
+		headerFilters:            []*headerFilter{},
+		sampleRates:              map[int]float64{},
+		adaptiveSampler:          nil,
+		clientLimiter:            nil,
+		deduplicator:             nil,
+		summarizer:               nil,
+		normalizePaths:           false,
+		lowercasePaths:           false,
+		customFilter:             nil,
+		skippers:                 []Skipper{},
+		skipHealthChecks:         false,
+		malformedRequests:        false,
+		customLogger:             nil,
+		beforeLog:                []BeforeLog{},
+		onLog:                    []OnLog{},
+		errorLogger:              nil,
+		minLevel:                 nil,
+		control:                  nil,
+		requestContext:           true,
+		customFields:             nil,
+		staticAttrs:              []slog.Attr{},
+		messageFunc:              func(c *gin.Context) string { return DefaultMessage },
+		requestStartLog:          false,
+		longLivedConnections:     false,
+		requestStartLevel:        slog.LevelInfo,
+		attrHook:                 nil,
+		errorsOnlyFields:         map[string]struct{}{},
+		errorsOnlyStatus:         0,
+		ipField:                  true,
+		ipAnonymizer:             nil,
+		statusField:              true,
+		methodField:              true,
+		pathField:                true,
+		userAgentField:           true,
+		parseUserAgent:           false,
+		latencyField:             true,
+		prettyLatency:            false,
+		bytesInField:             true,
+		bytesInRead:              false,
+		bytesOutField:            true,
+		requestIDField:           true,
+		groups:                   false,
+		fieldNames:               map[Field]string{},
+		otelFields:               false,
+		routeParamsField:         false,
+		hostField:                false,
+		refererField:             false,
+		protoField:               false,
+		tlsField:                 false,
+		startTimeField:           false,
+		startTimeLayout:          "",
+		startTimeUTC:             false,
+		requestHeaders:           []string{},
+		responseHeaders:          []string{},
+		redactor:                 redact.New(),
+		unredactedHeaders:        map[string]struct{}{},
+		contentTypeField:         false,
+		errorsField:              false,
+		userExtractor:            nil,
+		remoteAddrField:          false,
+		forwardedForField:        false,
+		handlerField:             false,
+		cookiesField:             false,
+		ttfbField:                false,
+		requestBodyMaxSize:       0,
+		requestBodyContentTypes:  []string{},
+		responseBodyMaxSize:      0,
+		responseBodyContentTypes: []string{},
+		requestDigestMaxSize:     0,
+		bodyStatuses:             []*regexp.Regexp{},
+		ipEnricher:               nil,
+		abortedField:             false,
+		abortedStatus:            0,
+		deadlineField:            false,
+		correlationIDHeader:      "",
+		contextAttrs:             []*contextAttr{},
+		requestIDOptions:         []requestid.ConfigOption{},
 	}
 }
 
@@ -124,24 +531,219 @@ func (c *Config) isDefaultFields() bool {
 		c.pathField ||
 		c.userAgentField ||
 		c.latencyField ||
+		c.bytesInField ||
+		c.bytesOutField ||
 		c.requestIDField
 }
 
+// isOptionalFields checks if any optional fields are used.
+func (c *Config) isOptionalFields() bool {
+	return c.abortedField ||
+		c.deadlineField ||
+		c.routeParamsField ||
+		c.hostField ||
+		c.refererField ||
+		c.protoField ||
+		c.tlsField ||
+		c.startTimeField ||
+		len(c.requestHeaders) > 0 ||
+		len(c.responseHeaders) > 0 ||
+		c.contentTypeField ||
+		c.errorsField ||
+		c.userExtractor != nil ||
+		c.remoteAddrField ||
+		c.forwardedForField ||
+		c.handlerField ||
+		c.cookiesField ||
+		c.ttfbField ||
+		c.requestBodyMaxSize > 0 ||
+		c.responseBodyMaxSize > 0 ||
+		c.requestDigestMaxSize > 0 ||
+		c.ipEnricher != nil ||
+		c.correlationIDHeader != "" ||
+		len(c.contextAttrs) > 0
+}
+
+// logContext returns the context passed to the slog handler for the request.
+func (c *Config) logContext(ctx *gin.Context) context.Context {
+	if c.requestContext {
+		return ctx.Request.Context()
+	}
+	return context.Background()
+}
+
+// level returns the log level of the request.
+func (c *Config) level(ctx *gin.Context, latency time.Duration) slog.Level {
+	// Use the level function if set
+	if c.levelFunc != nil {
+		return c.levelFunc(ctx)
+	}
+
+	// Check the path levels
+	path := c.normalizePath(ctx.Request.URL.Path)
+	for _, pathLevel := range c.pathLevels {
+		if pathLevel.regexp.MatchString(path) {
+			return pathLevel.level
+		}
+	}
+
+	// Check the HTTP return code levels
+	// If no status code matched, use the default level
+	level := c.defaultLevel.Level()
+	for _, httpLevel := range c.httpLevels {
+		if httpLevel.match(ctx.Writer.Status()) {
+			level = httpLevel.level.Level()
+			break
+		}
+	}
+
+	// Check the HTTP method levels, the errors keep their level
+	if methodLevel, ok := c.methodLevels[ctx.Request.Method]; ok && level < slog.LevelWarn {
+		level = methodLevel
+	}
+
+	// Escalate the slow requests level
+	for _, slowLevel := range c.slowLevels {
+		if latency > slowLevel.threshold {
+			level = max(level, slowLevel.level)
+		}
+	}
+
+	return level
+}
+
+// isSkipped checks if the log line is skipped by a skipper.
+func (c *Config) isSkipped(ctx *gin.Context) bool {
+	for _, skipper := range c.skippers {
+		if skipper(ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterErrorsOnlyFields removes the errors only fields from the attributes
+// if the HTTP return code is lower than the errors only status.
+func (c *Config) filterErrorsOnlyFields(attributes []slog.Attr, status int) []slog.Attr {
+	if len(c.errorsOnlyFields) == 0 || status >= c.errorsOnlyStatus {
+		return attributes
+	}
+	filtered := attributes[:0]
+	for _, attr := range attributes {
+		if _, ok := c.errorsOnlyFields[attr.Key]; !ok {
+			filtered = append(filtered, attr)
+		}
+	}
+	return filtered
+}
+
+// isBodyStatus checks if the bodies are logged for an HTTP return code.
+func (c *Config) isBodyStatus(code int) bool {
+	if len(c.bodyStatuses) == 0 {
+		return true
+	}
+	status := strconv.Itoa(code)
+	for _, v := range c.bodyStatuses {
+		if v.MatchString(status) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHealthCheck checks if the request is a health check, from its normalized
+// path and its user agent.
+func (c *Config) isHealthCheck(path, userAgent string) bool {
+	if !c.skipHealthChecks {
+		return false
+	}
+	if _, ok := healthCheckPaths[path]; ok {
+		return true
+	}
+	return strings.HasPrefix(userAgent, "kube-probe/")
+}
+
+// isExcludedPath checks if a path is excluded by the whitelist or the blacklist.
+func (c *Config) isExcludedPath(path string) bool {
+	// Check if the path is whitelisted
+	if len(c.whitelistPaths) > 0 {
+		whitelisted := false
+		for _, match := range c.whitelistPaths {
+			if match(path) {
+				whitelisted = true
+				break
+			}
+		}
+		if !whitelisted {
+			return true
+		}
+	}
+
+	// Check if the path is blacklisted
+	for _, match := range c.blacklistPaths {
+		if match(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isExcludedUserAgent checks if the user agent is blacklisted.
+func (c *Config) isExcludedUserAgent(userAgent string) bool {
+	for _, v := range c.blacklistUserAgents {
+		if v.MatchString(userAgent) {
+			return true
+		}
+	}
+	return false
+}
+
+// isExcludedHeader checks if the request is excluded by the header filters.
+func (c *Config) isExcludedHeader(header http.Header) bool {
+	for _, f := range c.headerFilters {
+		if f.regexp.MatchString(header.Get(f.header)) != f.include {
+			return true
+		}
+	}
+	return false
+}
+
+// isSampledOut checks if the request is dropped by the sampling.
+func (c *Config) isSampledOut(status int) bool {
+	rate, ok := c.sampleRates[status/100]
+	return ok && rand.Float64() >= rate
+}
+
 // validate validates the Config.
 func (c *Config) validate() {
 	if len(c.whitelistPaths) != 0 && len(c.blacklistPaths) != 0 {
 		panic("whitelist and blacklist can't be used together")
 	}
-	if !c.isDefaultFields() && c.customFields == nil {
+	if !c.isDefaultFields() && !c.isOptionalFields() && c.customFields == nil && len(c.staticAttrs) == 0 {
 		panic("no fields to log")
 	}
+	if c.messageFunc == nil {
+		panic("no message function")
+	}
+}
+
+// compileRegexps checks if the regexes are valid.
+func compileRegexps(exprs []string) error {
+	for _, expr := range exprs {
+		if _, err := regexp.Compile(expr); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ConfigOption allows to customize the middleware config.
 type ConfigOption func(*Config)
 
 // WithDefaultLevel allows to set the default log level.
-func WithDefaultLevel(level slog.Level) ConfigOption {
+// The level can be a slog.LevelVar to change it at runtime.
+func WithDefaultLevel(level slog.Leveler) ConfigOption {
 	return func(c *Config) {
 		c.defaultLevel = level
 	}
@@ -150,6 +752,27 @@ func WithDefaultLevel(level slog.Level) ConfigOption {
 // WithHTTPLevels allows to set the log level based on the HTTP return code.
 // The map key is a regex to match the HTTP return code. It panics if the regex is invalid.
 func WithHTTPLevels(httpLevels map[string]slog.Level) ConfigOption {
+	levelers := make(map[string]slog.Leveler, len(httpLevels))
+	for k, v := range httpLevels {
+		levelers[k] = v
+	}
+	return WithHTTPLevelers(levelers)
+}
+
+// TryWithHTTPLevels is like WithHTTPLevels but returns an error if a regex is
+// invalid instead of panicking.
+func TryWithHTTPLevels(httpLevels map[string]slog.Level) (ConfigOption, error) {
+	for expr := range httpLevels {
+		if err := compileRegexps([]string{expr}); err != nil {
+			return nil, err
+		}
+	}
+	return WithHTTPLevels(httpLevels), nil
+}
+
+// WithHTTPLevelers is like WithHTTPLevels but accepts slog.Leveler values
+// (e.g. slog.LevelVar) to change the levels at runtime.
+func WithHTTPLevelers(httpLevels map[string]slog.Leveler) ConfigOption {
 	return func(c *Config) {
 		c.httpLevels = []*httpLevel{}
 		for k, v := range httpLevels {
@@ -158,12 +781,77 @@ func WithHTTPLevels(httpLevels map[string]slog.Level) ConfigOption {
 	}
 }
 
+// WithStatusRangeLevels allows to set the log level based on ranges of HTTP
+// return codes. It is a typed and cheaper alternative to WithHTTPLevels.
+// The ranges are evaluated in order.
+func WithStatusRangeLevels(ranges []StatusRange) ConfigOption {
+	return func(c *Config) {
+		c.httpLevels = []*httpLevel{}
+		for _, r := range ranges {
+			c.httpLevels = append(c.httpLevels, newhttpRangeLevel(r.Min, r.Max, r.Level))
+		}
+	}
+}
+
+// WithLevelFunc allows to compute the log level of the request in a single
+// function (from the status, the path, the headers, ...). When set, it
+// overrides the default, HTTP return code, path and method levels.
+func WithLevelFunc(levelFunc LevelFunc) ConfigOption {
+	return func(c *Config) {
+		c.levelFunc = levelFunc
+	}
+}
+
+// WithPathLevels allows to set the log level based on the path (e.g. to log
+// a noisy endpoint at DEBUG level). The map key is a regex to match the path,
+// the regexes are evaluated in lexical order. The path levels are evaluated
+// before the HTTP return code levels. It panics if a regex is invalid.
+func WithPathLevels(pathLevels map[string]slog.Level) ConfigOption {
+	return func(c *Config) {
+		paths := make([]string, 0, len(pathLevels))
+		for k := range pathLevels {
+			paths = append(paths, k)
+		}
+		slices.Sort(paths)
+
+		c.pathLevels = []*pathLevel{}
+		for _, k := range paths {
+			c.pathLevels = append(c.pathLevels, &pathLevel{level: pathLevels[k], regexp: regexp.MustCompile(k)})
+		}
+	}
+}
+
+// WithMethodLevels allows to set the log level based on the HTTP method
+// (e.g. to log the GET requests at DEBUG level and the mutations at INFO level).
+// The method level replaces the HTTP return code level when it is lower than
+// WARN, so the errors are still logged at their level.
+func WithMethodLevels(methodLevels map[string]slog.Level) ConfigOption {
+	return func(c *Config) {
+		c.methodLevels = map[string]slog.Level{}
+		for k, v := range methodLevels {
+			c.methodLevels[strings.ToUpper(k)] = v
+		}
+	}
+}
+
+// WithSlowRequestThresholds allows to escalate the log level of the slow
+// requests (e.g. WARN above 1s and ERROR above 5s), even if the HTTP return
+// code is successful. The level is never lowered.
+func WithSlowRequestThresholds(thresholds map[time.Duration]slog.Level) ConfigOption {
+	return func(c *Config) {
+		c.slowLevels = []*slowLevel{}
+		for k, v := range thresholds {
+			c.slowLevels = append(c.slowLevels, &slowLevel{level: v, threshold: k})
+		}
+	}
+}
+
 // WithWhitelistPath allows to whitelist paths. It panics if the regex is invalid.
 // If a whitelist is set, only whitelisted paths are logged.
 func WithWhitelistPath(whitelistPath []string) ConfigOption {
 	return func(c *Config) {
 		for _, v := range whitelistPath {
-			c.whitelistPaths = append(c.whitelistPaths, regexp.MustCompile(v))
+			c.whitelistPaths = append(c.whitelistPaths, regexpPathMatcher(v))
 		}
 	}
 }
@@ -173,11 +861,164 @@ func WithWhitelistPath(whitelistPath []string) ConfigOption {
 func WithBlacklistPath(blacklistPath []string) ConfigOption {
 	return func(c *Config) {
 		for _, v := range blacklistPath {
-			c.blacklistPaths = append(c.blacklistPaths, regexp.MustCompile(v))
+			c.blacklistPaths = append(c.blacklistPaths, regexpPathMatcher(v))
 		}
 	}
 }
 
+// TryWithWhitelistPath is like WithWhitelistPath but returns an error if a
+// regex is invalid instead of panicking.
+func TryWithWhitelistPath(whitelistPath []string) (ConfigOption, error) {
+	if err := compileRegexps(whitelistPath); err != nil {
+		return nil, err
+	}
+	return WithWhitelistPath(whitelistPath), nil
+}
+
+// TryWithBlacklistPath is like WithBlacklistPath but returns an error if a
+// regex is invalid instead of panicking.
+func TryWithBlacklistPath(blacklistPath []string) (ConfigOption, error) {
+	if err := compileRegexps(blacklistPath); err != nil {
+		return nil, err
+	}
+	return WithBlacklistPath(blacklistPath), nil
+}
+
+// WithWhitelistPathExact allows to whitelist paths matched exactly,
+// without regex escaping and cheaper than WithWhitelistPath.
+func WithWhitelistPathExact(whitelistPath []string) ConfigOption {
+	return func(c *Config) {
+		c.whitelistPaths = append(c.whitelistPaths, exactPathMatcher(whitelistPath))
+	}
+}
+
+// WithWhitelistPathGlob allows to whitelist paths matched with shell patterns
+// (e.g. "/api/*/users", see path.Match). It panics if a pattern is invalid.
+func WithWhitelistPathGlob(whitelistPath []string) ConfigOption {
+	return func(c *Config) {
+		for _, v := range whitelistPath {
+			c.whitelistPaths = append(c.whitelistPaths, globPathMatcher(v))
+		}
+	}
+}
+
+// WithBlacklistPathExact allows to blacklist paths matched exactly,
+// without regex escaping and cheaper than WithBlacklistPath.
+func WithBlacklistPathExact(blacklistPath []string) ConfigOption {
+	return func(c *Config) {
+		c.blacklistPaths = append(c.blacklistPaths, exactPathMatcher(blacklistPath))
+	}
+}
+
+// WithBlacklistPathGlob allows to blacklist paths matched with shell patterns
+// (e.g. "/static/*", see path.Match). It panics if a pattern is invalid.
+func WithBlacklistPathGlob(blacklistPath []string) ConfigOption {
+	return func(c *Config) {
+		for _, v := range blacklistPath {
+			c.blacklistPaths = append(c.blacklistPaths, globPathMatcher(v))
+		}
+	}
+}
+
+// WithBlacklistUserAgents allows to blacklist user agents (uptime monitors,
+// load balancer probes, crawlers, ...). It panics if a regex is invalid.
+func WithBlacklistUserAgents(blacklistUserAgents []string) ConfigOption {
+	return func(c *Config) {
+		for _, v := range blacklistUserAgents {
+			c.blacklistUserAgents = append(c.blacklistUserAgents, regexp.MustCompile(v))
+		}
+	}
+}
+
+// WithHeaderFilter allows to filter the requests based on a request header
+// value (e.g. X-Internal: true), independently of the path. If include is
+// true, only the requests whose header value matches the regex are logged,
+// otherwise they are skipped. A missing header has an empty value.
+// It panics if the regex is invalid.
+func WithHeaderFilter(header, regex string, include bool) ConfigOption {
+	return func(c *Config) {
+		c.headerFilters = append(c.headerFilters, &headerFilter{
+			header:  http.CanonicalHeaderKey(header),
+			regexp:  regexp.MustCompile(regex),
+			include: include,
+		})
+	}
+}
+
+// WithSampling allows to log only a fraction of the successful requests
+// (1XX, 2XX and 3XX return codes), between 0 and 1, while all the errors are
+// logged. For example, 0.1 logs 1 in 10 successful requests.
+func WithSampling(rate float64) ConfigOption {
+	return WithClassSampling(map[int]float64{1: rate, 2: rate, 3: rate})
+}
+
+// WithClassSampling allows to set the sampling rate, between 0 and 1, by HTTP
+// return code class: the map key is the class (2 for 2XX, 4 for 4XX, ...).
+// The classes without rate are all logged.
+func WithClassSampling(rates map[int]float64) ConfigOption {
+	return func(c *Config) {
+		for k, v := range rates {
+			c.sampleRates[k] = v
+		}
+	}
+}
+
+// WithAdaptiveSampling allows to target a maximum number of log lines per
+// second: when the traffic spikes, the sampling rate of the successful
+// requests (1XX, 2XX and 3XX return codes) is reduced, while all the errors
+// are logged. The effective sampling rate is logged in the sample-rate field.
+func WithAdaptiveSampling(maxPerSecond int) ConfigOption {
+	return func(c *Config) {
+		c.adaptiveSampler = newAdaptiveSampler(maxPerSecond)
+	}
+}
+
+// WithClientRateLimit allows to log at most limit lines per client IP address
+// and interval, to prevent a single client from flooding the logs. At the
+// start of the next interval, a "Log lines suppressed" line is logged at WARN
+// level for each limited client with the number of suppressed lines.
+func WithClientRateLimit(limit int, interval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.clientLimiter = newClientLimiter(limit, interval)
+	}
+}
+
+// WithDeduplication allows to suppress the identical log lines (same path,
+// status and level) within the given window, e.g. during retry storms. The
+// first line is logged as usual, so it is never delayed, and the duplicates
+// are suppressed. Once the window is over, a second "Duplicate requests" line
+// is logged at the same level with the path, the status and the number of
+// suppressed duplicates in the count field. It is logged by the first request
+// after the window or by Controller.Update. No line is added if there is no
+// duplicate.
+func WithDeduplication(window time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.deduplicator = newDeduplicator(window)
+	}
+}
+
+// WithSummary allows to log a "Requests summary" line per route every
+// interval with the number of requests, of client and server errors and the
+// latency percentiles. The requests not matching any route are aggregated in
+// the <unmatched> route. The summary is logged by the first request after the
+// interval. If requests is false, only the summaries are logged.
+func WithSummary(interval time.Duration, requests bool) ConfigOption {
+	return func(c *Config) {
+		c.summarizer = newSummarizer(interval, requests)
+	}
+}
+
+// WithPathNormalization allows to normalize the paths before applying the
+// whitelist, the blacklist and the path levels and before logging the path
+// field: the duplicate slashes are collapsed, the trailing slash is stripped
+// and, if lowercase is true, the path is lowercased.
+func WithPathNormalization(lowercase bool) ConfigOption {
+	return func(c *Config) {
+		c.normalizePaths = true
+		c.lowercasePaths = lowercase
+	}
+}
+
 // WithCustomFilter allows to set a custom filter function.
 func WithCustomFilter(customFilter CustomFilter) ConfigOption {
 	return func(c *Config) {
@@ -185,13 +1026,117 @@ func WithCustomFilter(customFilter CustomFilter) ConfigOption {
 	}
 }
 
-// WithCustomLogger allows to set a custom logger function.
+// WithSkipper allows to skip the log line based on anything available after
+// the handlers (headers, status, authentication state, ...). It can be used
+// multiple times, the line is skipped if any skipper returns true.
+func WithSkipper(skipper Skipper) ConfigOption {
+	return func(c *Config) {
+		c.skippers = append(c.skippers, skipper)
+	}
+}
+
+// WithSkipStatus allows to skip the log lines of the given HTTP return codes
+// (e.g. 200 and 304 for the cache hits and the health checks) while still
+// logging the errors on the same paths.
+func WithSkipStatus(codes []int) ConfigOption {
+	return func(c *Config) {
+		statuses := make(map[int]struct{}, len(codes))
+		for _, code := range codes {
+			statuses[code] = struct{}{}
+		}
+		c.skippers = append(c.skippers, func(ctx *gin.Context) bool {
+			_, ok := statuses[ctx.Writer.Status()]
+			return ok
+		})
+	}
+}
+
+// WithSkipHealthChecks allows to skip the log lines of the common probe paths
+// (/healthz, /livez, /readyz, /ping and /metrics) and of the Kubernetes
+// probes (kube-probe user agent). The paths are matched once normalized, see
+// WithPathNormalization, and the probes are excluded before the request ID is
+// set.
+func WithSkipHealthChecks() ConfigOption {
+	return func(c *Config) {
+		c.skipHealthChecks = true
+	}
+}
+
+// WithMalformedRequests allows to always log the requests for which gin reports
+// a bind (parse) error, even if they are filtered out by the whitelist, the
+// blacklist or the custom filter. The bind errors are added to the log line
+// in the malformed field. As the bind errors are only known once the request
+// is processed, the request ID is also set for the excluded paths.
+func WithMalformedRequests() ConfigOption {
+	return func(c *Config) {
+		c.malformedRequests = true
+	}
+}
+
+// WithCustomLogger allows to set a callback called after the log line is
+// emitted (e.g. to log it to a second logger). It is not called when the
+// request is not logged.
 func WithCustomLogger(customLogger CustomLogger) ConfigOption {
 	return func(c *Config) {
 		c.customLogger = customLogger
 	}
 }
 
+// WithBeforeLog allows to add a hook called when all the fields of the log line
+// are built, just before it is emitted. It can modify the level, the message or
+// the fields of the Record, and returns false to cancel the log line (e.g. for
+// suppression rules that the filters can't express). The hooks are called in
+// order until one returns false.
+func WithBeforeLog(beforeLog BeforeLog) ConfigOption {
+	return func(c *Config) {
+		c.beforeLog = append(c.beforeLog, beforeLog)
+	}
+}
+
+// WithOnLog allows to add a hook called after each log line is emitted with its
+// level and its fields, e.g. to increment Prometheus counters or annotate spans
+// without measuring the latency again. The hooks are called in order.
+func WithOnLog(onLog OnLog) ConfigOption {
+	return func(c *Config) {
+		c.onLog = append(c.onLog, onLog)
+	}
+}
+
+// WithMinLevel allows to not log the requests with a level below the given
+// level. The level can be a slog.LevelVar to change it at runtime.
+func WithMinLevel(level slog.Leveler) ConfigOption {
+	return func(c *Config) {
+		c.minLevel = level
+	}
+}
+
+// WithControl allows to change the minimum level, the sample rate and the
+// blacklist at runtime with the given Control (see ControlHandler). They apply
+// on top of the other options.
+func WithControl(control *Control) ConfigOption {
+	return func(c *Config) {
+		c.control = control
+	}
+}
+
+// WithErrorLogger allows to log the requests with a 5XX HTTP return code,
+// including the recovered panics, with the given logger instead of the
+// middleware logger, e.g. to send them to an alerting sink.
+func WithErrorLogger(logger *slog.Logger) ConfigOption {
+	return func(c *Config) {
+		c.errorLogger = logger
+	}
+}
+
+// WithoutRequestContext allows to pass context.Background to the slog handler
+// instead of the request context. By default, the request context is passed so
+// the context-aware handlers (trace correlation, ...) receive it.
+func WithoutRequestContext() ConfigOption {
+	return func(c *Config) {
+		c.requestContext = false
+	}
+}
+
 // WithCustomFields allows to set a custom function to add custom fields to the log line.
 func WithCustomFields(customFields CustomFields) ConfigOption {
 	return func(c *Config) {
@@ -199,6 +1144,424 @@ func WithCustomFields(customFields CustomFields) ConfigOption {
 	}
 }
 
+// WithStaticAttrs allows to add constant fields to every log line
+// (service name, version, environment, region, ...).
+func WithStaticAttrs(attrs []slog.Attr) ConfigOption {
+	return func(c *Config) {
+		c.staticAttrs = append(c.staticAttrs, attrs...)
+	}
+}
+
+// WithMessage allows to replace the default message of the log line.
+func WithMessage(message string) ConfigOption {
+	return func(c *Config) {
+		c.messageFunc = func(*gin.Context) string { return message }
+	}
+}
+
+// WithMessageFunc allows to build the message of the log line from the
+// request (e.g. "GET /users 200"). It panics if the function is nil.
+func WithMessageFunc(messageFunc MessageFunc) ConfigOption {
+	return func(c *Config) {
+		c.messageFunc = messageFunc
+	}
+}
+
+// WithAttrHook allows to set a function called with the final fields just
+// before logging, to drop, rewrite, reorder or enrich them.
+func WithAttrHook(attrHook AttrHook) ConfigOption {
+	return func(c *Config) {
+		c.attrHook = attrHook
+	}
+}
+
+// WithErrorsOnlyFields allows to log the given fields (e.g. "user-agent",
+// "request-headers", "request-body") only when the HTTP return code is greater
+// than or equal to minStatus, to keep the success log lines small.
+// The fields are identified by their default names.
+func WithErrorsOnlyFields(minStatus int, fields []string) ConfigOption {
+	return func(c *Config) {
+		c.errorsOnlyStatus = minStatus
+		for _, f := range fields {
+			c.errorsOnlyFields[f] = struct{}{}
+		}
+	}
+}
+
+// WithRequestStartLog allows to log a "Request started" line at the given
+// level with the method, the path and the request ID before the handlers are
+// called, so the hung requests are visible before they complete.
+func WithRequestStartLog(level slog.Level) ConfigOption {
+	return func(c *Config) {
+		c.requestStartLog = true
+		c.requestStartLevel = level
+	}
+}
+
+// WithLongLivedConnections allows to log the WebSocket and Server-Sent Events
+// connections with a "Connection opened" line when they are opened and a
+// "Connection closed" line with the total duration and the bytes exchanged when
+// they are closed. The latency is logged as duration and is not used to
+// compute the log level.
+func WithLongLivedConnections() ConfigOption {
+	return func(c *Config) {
+		c.longLivedConnections = true
+	}
+}
+
+// WithTrustRequestID allows to reuse the X-Request-ID header sent by the client
+// instead of generating a new one, so IDs correlate across services.
+// By default, the client value is only reused if it is not longer than 128
+// characters and contains only alphanumeric characters, '-', '_', '.' or ':'.
+// Otherwise, a new request ID is generated. Use WithRequestIDOptions to
+// customize the validation.
+func WithTrustRequestID() ConfigOption {
+	return func(c *Config) {
+		c.requestIDOptions = append(c.requestIDOptions, requestid.WithTrustHeader())
+	}
+}
+
+// WithRequestIDOptions allows to customize the request ID set by the middleware
+// when the requestid middleware is not mounted before it.
+func WithRequestIDOptions(opts ...requestid.ConfigOption) ConfigOption {
+	return func(c *Config) {
+		c.requestIDOptions = append(c.requestIDOptions, opts...)
+	}
+}
+
+// WithRouteParams allows to add the gin route parameters to the log line
+// in the params group (e.g. params.id for the /orders/:id route).
+func WithRouteParams() ConfigOption {
+	return func(c *Config) {
+		c.routeParamsField = true
+	}
+}
+
+// WithHost allows to add the HTTP host to the log line.
+// It is useful when the same service serves several virtual hosts.
+func WithHost() ConfigOption {
+	return func(c *Config) {
+		c.hostField = true
+	}
+}
+
+// WithReferer allows to add the HTTP referer to the log line.
+func WithReferer() ConfigOption {
+	return func(c *Config) {
+		c.refererField = true
+	}
+}
+
+// WithProto allows to add the HTTP protocol version (e.g. HTTP/1.1, HTTP/2.0)
+// to the log line.
+func WithProto() ConfigOption {
+	return func(c *Config) {
+		c.protoField = true
+	}
+}
+
+// WithTLS allows to add the TLS version, cipher suite and server name (SNI)
+// to the log line in the tls group. It is useful to audit the deprecated TLS
+// versions. Nothing is added for plain HTTP requests.
+func WithTLS() ConfigOption {
+	return func(c *Config) {
+		c.tlsField = true
+	}
+}
+
+// WithStartTime allows to add the request start time to the log line.
+// Unlike the log record time which is the request completion time, it is the
+// request arrival time. The time is formatted with the given layout
+// (e.g. time.RFC3339Nano), or by the slog handler if the layout is empty.
+func WithStartTime(layout string) ConfigOption {
+	return func(c *Config) {
+		c.startTimeField = true
+		c.startTimeLayout = layout
+	}
+}
+
+// WithStartTimeUTC allows to convert the request start time to UTC.
+// It is used with WithStartTime.
+func WithStartTimeUTC() ConfigOption {
+	return func(c *Config) {
+		c.startTimeUTC = true
+	}
+}
+
+// WithRequestHeaders allows to add the given request headers to the log line
+// in the request-headers group. The sensitive headers values are redacted,
+// see WithRedactor and WithUnredactedHeaders.
+func WithRequestHeaders(headers []string) ConfigOption {
+	return func(c *Config) {
+		c.requestHeaders = append(c.requestHeaders, canonicalHeaders(headers)...)
+	}
+}
+
+// WithResponseHeaders allows to add the given response headers to the log line
+// in the response-headers group. The sensitive headers values are redacted,
+// see WithRedactor and WithUnredactedHeaders.
+func WithResponseHeaders(headers []string) ConfigOption {
+	return func(c *Config) {
+		c.responseHeaders = append(c.responseHeaders, canonicalHeaders(headers)...)
+	}
+}
+
+// WithUnredactedHeaders allows to log the given sensitive headers values
+// instead of redacting them.
+func WithUnredactedHeaders(headers []string) ConfigOption {
+	return func(c *Config) {
+		for _, h := range canonicalHeaders(headers) {
+			c.unredactedHeaders[h] = struct{}{}
+		}
+	}
+}
+
+// WithRedactor allows to set the redaction policy of the headers values,
+// the path and the referer. By default, only the redact.DefaultHeaders are
+// redacted.
+func WithRedactor(redactor *redact.Redactor) ConfigOption {
+	return func(c *Config) {
+		c.redactor = redactor
+	}
+}
+
+// WithContentType allows to add the response content type to the log line.
+// It is a lightweight alternative to WithResponseHeaders to segment the traffic
+// (API, HTML, file downloads, ...).
+func WithContentType() ConfigOption {
+	return func(c *Config) {
+		c.contentTypeField = true
+	}
+}
+
+// WithErrors allows to add the errors recorded by the handlers with
+// gin.Context.Error to the log line in the errors field.
+// Nothing is added if there are no errors.
+func WithErrors() ConfigOption {
+	return func(c *Config) {
+		c.errorsField = true
+	}
+}
+
+// WithUser allows to add the authenticated user to the log line in the user
+// field (e.g. UserFromBasicAuth or UserFromContextKey).
+// Nothing is added if the user is unknown.
+func WithUser(userExtractor UserExtractor) ConfigOption {
+	return func(c *Config) {
+		c.userExtractor = userExtractor
+	}
+}
+
+// WithRemoteAddr allows to add the remote address of the connection (IP and port)
+// to the log line in the remote-addr field. Unlike the ip field, it is not
+// resolved from the proxy headers, which helps to debug NAT and connection
+// reuse issues behind load balancers.
+func WithRemoteAddr() ConfigOption {
+	return func(c *Config) {
+		c.remoteAddrField = true
+	}
+}
+
+// WithForwardedFor allows to add the full X-Forwarded-For chain to the log line
+// in the forwarded-for field, not only the resolved client IP. It helps to
+// investigate IP spoofing and proxy hops issues.
+// Nothing is added if the header is missing.
+func WithForwardedFor() ConfigOption {
+	return func(c *Config) {
+		c.forwardedForField = true
+	}
+}
+
+// WithHandlerName allows to add the name of the final gin handler to the log line
+// in the handler field, so logs map directly to code locations.
+func WithHandlerName() ConfigOption {
+	return func(c *Config) {
+		c.handlerField = true
+	}
+}
+
+// WithCookies allows to add the names of the cookies sent with the request to the
+// log line in the cookies field. The cookies values are never logged.
+// Nothing is added if there are no cookies.
+func WithCookies() ConfigOption {
+	return func(c *Config) {
+		c.cookiesField = true
+	}
+}
+
+// WithTTFB allows to add the time to first byte to the log line in the ttfb
+// field, it is the time elapsed until the response headers or body are first
+// written. Compared to the latency, it distinguishes slow handlers from slow
+// streaming responses. Nothing is added if the handlers write nothing.
+func WithTTFB() ConfigOption {
+	return func(c *Config) {
+		c.ttfbField = true
+	}
+}
+
+// WithRequestBody allows to add the request body to the log line in the
+// request-body field. Only the first maxSize bytes are logged and only if the
+// request media type is in the contentTypes allowlist (e.g. application/json,
+// text/*), all the media types are logged if the allowlist is empty.
+// The binary, multipart and compressed bodies are not read, only their content
+// type and size are logged.
+// It should be used for debugging purpose in non-production environments.
+func WithRequestBody(maxSize int, contentTypes []string) ConfigOption {
+	return func(c *Config) {
+		c.requestBodyMaxSize = maxSize
+		c.requestBodyContentTypes = contentTypes
+	}
+}
+
+// WithResponseBody allows to add the response body to the log line in the
+// response-body field. Only the first maxSize bytes are logged and only if the
+// response media type is in the contentTypes allowlist (e.g. application/json,
+// text/*), all the media types are logged if the allowlist is empty.
+// The binary, multipart and compressed bodies are not captured, only their
+// content type and size are logged.
+// It should be used for debugging purpose in non-production environments.
+func WithResponseBody(maxSize int, contentTypes []string) ConfigOption {
+	return func(c *Config) {
+		c.responseBodyMaxSize = maxSize
+		c.responseBodyContentTypes = contentTypes
+	}
+}
+
+// WithRequestDigest allows to add the SHA-256 digest and the size of the request
+// body to the log line in the request-digest group, instead of the body itself.
+// It gives tamper-evidence and deduplication capabilities for audit purposes
+// without storing the payloads. The bytes not read by the handlers are read
+// by the middleware to compute the digest of the whole body, up to maxSize
+// bytes. If the body is larger, the digest is computed on the bytes read and
+// partial is set to true.
+func WithRequestDigest(maxSize int) ConfigOption {
+	return func(c *Config) {
+		c.requestDigestMaxSize = maxSize
+	}
+}
+
+// WithBodyStatus allows to log the request and response bodies only when the
+// HTTP return code matches one of the given regexes (e.g. HTTPClientErrorRegex,
+// HTTPServerErrorRegex). It panics if a regex is invalid.
+// The response body is not captured if the code does not match, the request
+// body is still read but not logged.
+func WithBodyStatus(statuses []string) ConfigOption {
+	return func(c *Config) {
+		for _, v := range statuses {
+			c.bodyStatuses = append(c.bodyStatuses, regexp.MustCompile(v))
+		}
+	}
+}
+
+// WithIPEnricher allows to add fields based on the client IP address, the
+// function is called with the IP address resolved by gin (c.ClientIP()).
+// It can be used to plug a GeoIP lookup to log the country or the ASN.
+func WithIPEnricher(ipEnricher IPEnricher) ConfigOption {
+	return func(c *Config) {
+		c.ipEnricher = ipEnricher
+	}
+}
+
+// WithCorrelationID allows to log the correlation ID sent by the client in the
+// given HTTP header (e.g. HeaderCorrelationID). Unlike the request ID, the
+// correlation ID is long-lived and shared by all the requests of a business
+// transaction. It is logged in the correlation-id field when present.
+func WithCorrelationID(header string) ConfigOption {
+	return func(c *Config) {
+		c.correlationIDHeader = header
+	}
+}
+
+// WithContextAttrs allows to log the values set on the request context by the
+// previous middlewares (tenant, user, trace, ...). The map key is the request
+// context key and the map value is the field name. The fields are sorted by
+// name and are not logged if the value is not set.
+func WithContextAttrs(contextAttrs map[any]string) ConfigOption {
+	return func(c *Config) {
+		c.contextAttrs = []*contextAttr{}
+		for k, v := range contextAttrs {
+			c.contextAttrs = append(c.contextAttrs, &contextAttr{key: k, name: v})
+		}
+		slices.SortFunc(c.contextAttrs, func(a, b *contextAttr) int {
+			return strings.Compare(a.name, b.name)
+		})
+	}
+}
+
+// WithClientAborts allows to log aborted=true when the client went away before
+// the response was sent (the request context is canceled), since gin reports
+// the status set by the handlers. If status is not 0 (e.g. 499), it is logged
+// instead of the HTTP return code for the aborted requests.
+func WithClientAborts(status int) ConfigOption {
+	return func(c *Config) {
+		c.abortedField = true
+		c.abortedStatus = status
+	}
+}
+
+// WithDeadline allows to log, when the request context has a deadline (e.g.
+// set by a timeout middleware), whether it was exceeded and the remaining
+// time in the deadline group, to distinguish the timeouts from the handlers
+// errors.
+func WithDeadline() ConfigOption {
+	return func(c *Config) {
+		c.deadlineField = true
+	}
+}
+
+// WithBytesInRead allows to log the number of request body bytes actually read
+// by the handlers instead of the Content-Length header, which is unknown for
+// chunked requests and may not match the body.
+func WithBytesInRead() ConfigOption {
+	return func(c *Config) {
+		c.bytesInRead = true
+	}
+}
+
+// WithGroups allows to group the default fields in the log line:
+// the ip and user-agent fields under the client group and the other
+// default fields under the http group.
+func WithGroups() ConfigOption {
+	return func(c *Config) {
+		c.groups = true
+	}
+}
+
+// WithFieldNames allows to rename the given default fields
+// (e.g. FieldStatus to "code", FieldUserAgent to "ua").
+func WithFieldNames(names map[Field]string) ConfigOption {
+	return func(c *Config) {
+		for field, name := range names {
+			c.fieldNames[field] = name
+		}
+	}
+}
+
+// WithECSFields allows to name the default fields according to the
+// Elastic Common Schema (client.ip, http.response.status_code,
+// http.request.method, url.path, event.duration, ...). The default fields
+// are not grouped, see WithGroups.
+func WithECSFields() ConfigOption {
+	return func(c *Config) {
+		c.groups = false
+		c.fieldNames = maps.Clone(ecsFieldNames)
+	}
+}
+
+// WithOTelSemConv allows to name the default fields according to the
+// OpenTelemetry HTTP semantic conventions (client.address,
+// http.response.status_code, http.request.method, url.path, ...) and to add
+// the http.route and network.peer.address fields. The latency is logged in
+// seconds as a float, even with WithPrettyLatency. The default fields are
+// not grouped, see WithGroups.
+func WithOTelSemConv() ConfigOption {
+	return func(c *Config) {
+		c.groups = false
+		c.fieldNames = maps.Clone(otelFieldNames)
+		c.otelFields = true
+	}
+}
+
 // WithoutDefaultFields to not use the default fields in the log line.
 func WithoutDefaultFields() ConfigOption {
 	return func(c *Config) {
@@ -208,10 +1571,33 @@ func WithoutDefaultFields() ConfigOption {
 		c.pathField = false
 		c.userAgentField = false
 		c.latencyField = false
+		c.bytesInField = false
+		c.bytesOutField = false
 		c.requestIDField = false
 	}
 }
 
+// WithFields allows to log only the given default fields.
+// It panics if a field is not a default field.
+func WithFields(fields []Field) ConfigOption {
+	for _, f := range fields {
+		if !isDefaultField(f) {
+			panic(fmt.Sprintf("unknown field: %s", f))
+		}
+	}
+	return func(c *Config) {
+		c.ipField = slices.Contains(fields, FieldIP)
+		c.statusField = slices.Contains(fields, FieldStatus)
+		c.methodField = slices.Contains(fields, FieldMethod)
+		c.pathField = slices.Contains(fields, FieldPath)
+		c.userAgentField = slices.Contains(fields, FieldUserAgent)
+		c.latencyField = slices.Contains(fields, FieldLatency)
+		c.bytesInField = slices.Contains(fields, FieldBytesIn)
+		c.bytesOutField = slices.Contains(fields, FieldBytesOut)
+		c.requestIDField = slices.Contains(fields, FieldRequestID)
+	}
+}
+
 // WithoutIP to not add the IP address to the log line.
 func WithoutIP() ConfigOption {
 	return func(c *Config) {
@@ -219,6 +1605,16 @@ func WithoutIP() ConfigOption {
 	}
 }
 
+// WithIPAnonymizer allows to anonymize the client IP addresses logged in the ip,
+// remote-addr and forwarded-for fields (e.g. TruncateIP(24, 48) or HashIP(key)),
+// so per-client analytics can be kept without storing personal data.
+// The IP enricher still receives the original IP address.
+func WithIPAnonymizer(ipAnonymizer IPAnonymizer) ConfigOption {
+	return func(c *Config) {
+		c.ipAnonymizer = ipAnonymizer
+	}
+}
+
 // WithoutStatus to not add the HTTP return code to the log line.
 func WithoutStatus() ConfigOption {
 	return func(c *Config) {
@@ -247,6 +1643,15 @@ func WithoutUserAgent() ConfigOption {
 	}
 }
 
+// WithParsedUserAgent allows to log the user agent as a group with the browser,
+// the operating system, the device type (desktop, mobile, tablet or bot) and
+// the bot classification, instead of the raw string.
+func WithParsedUserAgent() ConfigOption {
+	return func(c *Config) {
+		c.parseUserAgent = true
+	}
+}
+
 // WithoutLatency to not add the request latency to the log line.
 func WithoutLatency() ConfigOption {
 	return func(c *Config) {
@@ -254,6 +1659,29 @@ func WithoutLatency() ConfigOption {
 	}
 }
 
+// WithPrettyLatency allows to log the latency as a human readable string
+// (e.g. 1.5ms) instead of a duration, which the JSON handler logs in
+// nanoseconds.
+func WithPrettyLatency() ConfigOption {
+	return func(c *Config) {
+		c.prettyLatency = true
+	}
+}
+
+// WithoutBytesIn to not add the request body size to the log line.
+func WithoutBytesIn() ConfigOption {
+	return func(c *Config) {
+		c.bytesInField = false
+	}
+}
+
+// WithoutBytesOut to not add the response body size to the log line.
+func WithoutBytesOut() ConfigOption {
+	return func(c *Config) {
+		c.bytesOutField = false
+	}
+}
+
 // WithoutRequestID to not add the X-Request-ID header to the log line.
 func WithoutRequestID() ConfigOption {
 	return func(c *Config) {
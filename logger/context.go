@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loggerKey is the key used to store the request logger in the gin context.
+const loggerKey = "ginslog/logger"
+
+// requestLogger builds the request logger on the first FromContext call, so
+// the requests whose handlers don't use it don't pay for it.
+type requestLogger struct {
+	once sync.Once
+
+	// Logger of the middleware.
+	parent *slog.Logger
+	// Middleware configuration.
+	config *Config
+	// Request ID of the request.
+	requestID string
+
+	// Logger built from the parent logger.
+	logger *slog.Logger
+}
+
+// get returns the request logger, building it if needed.
+func (r *requestLogger) get(c *gin.Context) *slog.Logger {
+	r.once.Do(func() {
+		r.logger = slog.New(r.parent.Handler().WithAttrs(append(
+			r.config.formatDefaultFields([]slog.Attr{
+				slog.String("ip", r.config.anonymizeIP(c.ClientIP())),
+				slog.String("request-id", r.requestID),
+			}),
+			slog.String("route", c.FullPath()),
+		)))
+	})
+	return r.logger
+}
+
+// FromContext returns the request logger set by the logging middleware. It
+// logs the request ID, the route and the client IP address with every record.
+// It returns the middleware logger without these attributes if the path is
+// excluded, and slog.Default if the middleware is not used.
+func FromContext(c *gin.Context) *slog.Logger {
+	switch logger := c.Value(loggerKey).(type) {
+	case *requestLogger:
+		return logger.get(c)
+	case *slog.Logger:
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ControlState is the live logging configuration exposed by ControlHandler.
+type ControlState struct {
+	// Minimum level of the logged requests.
+	Level slog.Level `json:"level"`
+	// Fraction of the successful requests (1XX, 2XX and 3XX return codes)
+	// logged, between 0 and 1.
+	SampleRate float64 `json:"sample_rate"`
+	// Regexes matching the paths not logged.
+	Blacklist []string `json:"blacklist"`
+}
+
+// controlUpdate is the body of a ControlHandler PUT request,
+// the missing fields are not updated.
+type controlUpdate struct {
+	Level      *slog.Level `json:"level"`
+	SampleRate *float64    `json:"sample_rate"`
+	Blacklist  *[]string   `json:"blacklist"`
+}
+
+// Control holds the logging configuration that can be changed at runtime with
+// ControlHandler. It is safe for concurrent use.
+type Control struct {
+	mu sync.RWMutex
+
+	// Current state.
+	state ControlState
+	// Compiled blacklist regexes.
+	blacklist []*regexp.Regexp
+}
+
+// NewControl returns a new Control logging all the requests: the minimum
+// level is DEBUG, the sample rate is 1 and the blacklist is empty.
+// Use it with WithControl and ControlHandler.
+func NewControl() *Control {
+	return &Control{state: ControlState{Level: slog.LevelDebug, SampleRate: 1, Blacklist: []string{}}}
+}
+
+// State returns the current state.
+func (ctrl *Control) State() ControlState {
+	ctrl.mu.RLock()
+	defer ctrl.mu.RUnlock()
+
+	state := ctrl.state
+	state.Blacklist = slices.Clone(state.Blacklist)
+	return state
+}
+
+// update validates and applies the update.
+func (ctrl *Control) update(u controlUpdate) error {
+	var blacklist []*regexp.Regexp
+	if u.Blacklist != nil {
+		for _, expr := range *u.Blacklist {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return err
+			}
+			blacklist = append(blacklist, re)
+		}
+	}
+	if u.SampleRate != nil && (*u.SampleRate < 0 || *u.SampleRate > 1) {
+		return fmt.Errorf("invalid sample rate %v: must be between 0 and 1", *u.SampleRate)
+	}
+
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	if u.Level != nil {
+		ctrl.state.Level = *u.Level
+	}
+	if u.SampleRate != nil {
+		ctrl.state.SampleRate = *u.SampleRate
+	}
+	if u.Blacklist != nil {
+		ctrl.state.Blacklist = slices.Clone(*u.Blacklist)
+		ctrl.blacklist = blacklist
+	}
+	return nil
+}
+
+// isExcludedPath checks if the path is in the blacklist.
+func (ctrl *Control) isExcludedPath(path string) bool {
+	ctrl.mu.RLock()
+	defer ctrl.mu.RUnlock()
+
+	for _, re := range ctrl.blacklist {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSampledOut checks if the successful request is sampled out.
+func (ctrl *Control) isSampledOut(status int) bool {
+	ctrl.mu.RLock()
+	defer ctrl.mu.RUnlock()
+
+	return status < 400 && rand.Float64() >= ctrl.state.SampleRate
+}
+
+// isBelowLevel checks if the level is below the minimum level.
+func (ctrl *Control) isBelowLevel(level slog.Level) bool {
+	ctrl.mu.RLock()
+	defer ctrl.mu.RUnlock()
+
+	return level < ctrl.state.Level
+}
+
+// ControlHandler returns a gin.HandlerFunc exposing the Control state:
+// GET returns it as JSON and PUT updates the fields present in the JSON body
+// and returns the new state. It must be mounted on a protected route.
+func ControlHandler(ctrl *Control) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var u controlUpdate
+			if err := c.ShouldBindJSON(&u); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := ctrl.update(u); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		default:
+			c.Header("Allow", "GET, PUT")
+			c.AbortWithStatus(http.StatusMethodNotAllowed)
+			return
+		}
+		c.JSON(http.StatusOK, ctrl.State())
+	}
+}
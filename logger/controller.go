@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// controllerState is the Config used by the Controller middleware.
+type controllerState struct {
+	// Current Config.
+	config *Config
+	// Middleware built from the Config.
+	handler gin.HandlerFunc
+}
+
+// Controller allows to reconfigure the logging middleware without restarting
+// the server. The middleware reads its Config through an atomic pointer, so
+// the requests in flight keep the Config they started with.
+type Controller struct {
+	// Serializes the updates.
+	mu sync.Mutex
+
+	// Logger used by the middleware.
+	logger *slog.Logger
+	// Current state.
+	state atomic.Pointer[controllerState]
+}
+
+// NewController returns a new Controller with the given options,
+// see New for the default behavior. It panics if the options are invalid.
+func NewController(logger *slog.Logger, opts ...ConfigOption) *Controller {
+	config := NewConfig(opts...)
+	ctrl := &Controller{logger: logger}
+	ctrl.state.Store(&controllerState{config: config, handler: NewFromConfig(logger, config)})
+	return ctrl
+}
+
+// Handler returns a gin.HandlerFunc (middleware) that logs requests with the
+// current Config.
+func (ctrl *Controller) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctrl.state.Load().handler(c)
+	}
+}
+
+// Config returns the current Config.
+func (ctrl *Controller) Config() *Config {
+	return ctrl.state.Load().config
+}
+
+// Update applies the given options on top of the current options
+// (levels, filters, sampling, ...). The sampling, rate limiting, deduplication
+// and summary states are reset, the pending duplicates counts and the current
+// summary are logged first. It returns an error and keeps the current Config
+// if the new one is invalid.
+func (ctrl *Controller) Update(opts ...ConfigOption) error {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	previous := ctrl.state.Load().config
+	state, err := buildState(ctrl.logger, previous, opts)
+	if err != nil {
+		return err
+	}
+	ctrl.state.Store(state)
+
+	// Log the state of the previous Config
+	if previous.deduplicator != nil {
+		previous.logDuplicates(ctrl.logger, previous.deduplicator.flush())
+	}
+	if previous.summarizer != nil {
+		logSummary(ctrl.logger, previous.summarizer.flush())
+	}
+	return nil
+}
+
+// buildState returns the state of the options applied on top of the Config.
+// The options and the validation panic on invalid values, the panics are
+// returned as errors.
+func buildState(logger *slog.Logger, current *Config, opts []ConfigOption) (*controllerState, error) {
+	var err error
+	state := func() *controllerState {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("invalid config: %v", r)
+			}
+		}()
+
+		config := current.Clone(opts...)
+		return &controllerState{config: config, handler: NewFromConfig(logger, config)}
+	}()
+	return state, err
+}
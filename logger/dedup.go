@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// dedupKey identifies the identical log lines.
+type dedupKey struct {
+	path   string
+	status int
+	level  slog.Level
+}
+
+// dedupEntry counts the duplicates of a log line.
+type dedupEntry struct {
+	// Time of the first log line.
+	start time.Time
+	// Number of suppressed duplicates.
+	count int
+}
+
+// deduplicator suppresses the identical log lines within a window and counts
+// them, the count is logged in a separate line once the window is over.
+type deduplicator struct {
+	mu sync.Mutex
+
+	// Duration of the window.
+	window time.Duration
+	// Last time the expired entries were collected.
+	lastFlush time.Time
+	// Entries of the current windows.
+	entries map[dedupKey]*dedupEntry
+}
+
+// newDeduplicator returns a new deduplicator.
+func newDeduplicator(window time.Duration) *deduplicator {
+	return &deduplicator{window: window, entries: map[dedupKey]*dedupEntry{}}
+}
+
+// check checks if the log line is the first of its window. It also returns
+// the expired entries with duplicates, to log their count.
+func (d *deduplicator) check(key dedupKey, now time.Time) (bool, map[dedupKey]int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Collect the expired entries at most once per window
+	var expired map[dedupKey]int
+	if now.Sub(d.lastFlush) >= d.window {
+		for k, entry := range d.entries {
+			if now.Sub(entry.start) < d.window {
+				continue
+			}
+			if entry.count > 0 {
+				if expired == nil {
+					expired = map[dedupKey]int{}
+				}
+				expired[k] = entry.count
+			}
+			delete(d.entries, k)
+		}
+		d.lastFlush = now
+	}
+
+	// Count the duplicates in the window
+	if entry, ok := d.entries[key]; ok && now.Sub(entry.start) < d.window {
+		entry.count++
+		return false, expired
+	} else if ok && entry.count > 0 {
+		if expired == nil {
+			expired = map[dedupKey]int{}
+		}
+		expired[key] = entry.count
+	}
+	d.entries[key] = &dedupEntry{start: now}
+	return true, expired
+}
+
+// flush returns the entries with duplicates and resets the windows.
+func (d *deduplicator) flush() map[dedupKey]int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	pending := map[dedupKey]int{}
+	for k, entry := range d.entries {
+		if entry.count > 0 {
+			pending[k] = entry.count
+		}
+	}
+	d.entries = map[dedupKey]*dedupEntry{}
+	return pending
+}
+
+// logDuplicates logs the count of the suppressed duplicates.
+func (c *Config) logDuplicates(logger *slog.Logger, duplicates map[dedupKey]int) {
+	for key, count := range duplicates {
+		logger.LogAttrs(
+			context.Background(), key.level, "Duplicate requests",
+			slog.String("path", c.redactor.Path(key.path)),
+			slog.Int("status", key.status),
+			slog.Int("count", count),
+		)
+	}
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Environment variables read by ConfigFromEnv.
+const (
+	// EnvLevel is the minimum level of the logged requests (e.g. WARN).
+	EnvLevel = "GINSLOG_LEVEL"
+	// EnvBlacklist is a comma separated list of regexes of paths not logged.
+	EnvBlacklist = "GINSLOG_BLACKLIST"
+	// EnvFields is a comma separated list of the default fields to log
+	// (e.g. status,method,path,latency).
+	EnvFields = "GINSLOG_FIELDS"
+)
+
+// ConfigFromEnv returns the options set by the GINSLOG_LEVEL, GINSLOG_BLACKLIST
+// and GINSLOG_FIELDS environment variables, so the logging can be tuned per
+// deployment. The unset variables are ignored. It returns an error if a
+// variable is invalid.
+func ConfigFromEnv() ([]ConfigOption, error) {
+	opts := []ConfigOption{}
+
+	if v := os.Getenv(EnvLevel); v != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(v)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvLevel, err)
+		}
+		opts = append(opts, WithMinLevel(level))
+	}
+
+	if v := os.Getenv(EnvBlacklist); v != "" {
+		opt, err := TryWithBlacklistPath(splitList(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvBlacklist, err)
+		}
+		opts = append(opts, opt)
+	}
+
+	if v := os.Getenv(EnvFields); v != "" {
+		fields := []Field{}
+		for _, name := range splitList(v) {
+			if !isDefaultField(Field(name)) {
+				return nil, fmt.Errorf("invalid %s: unknown field %s", EnvFields, name)
+			}
+			fields = append(fields, Field(name))
+		}
+		opts = append(opts, WithFields(fields))
+	}
+
+	return opts, nil
+}
+
+// splitList splits a comma separated list and trims the spaces.
+func splitList(v string) []string {
+	list := []string{}
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,100 @@
+package logger
+
+import "log/slog"
+
+// Field represents a default field, see WithFieldNames.
+type Field string
+
+// Default fields.
+const (
+	FieldIP        Field = "ip"
+	FieldStatus    Field = "status"
+	FieldMethod    Field = "method"
+	FieldPath      Field = "path"
+	FieldUserAgent Field = "user-agent"
+	FieldLatency   Field = "latency"
+	FieldBytesIn   Field = "bytes-in"
+	FieldBytesOut  Field = "bytes-out"
+	FieldRequestID Field = "request-id"
+)
+
+// isDefaultField checks if the field is a default field.
+func isDefaultField(f Field) bool {
+	_, ok := defaultFieldsGroups[f]
+	return ok
+}
+
+// Groups of the default fields, see WithGroups.
+const (
+	clientGroup = "client"
+	httpGroup   = "http"
+)
+
+// defaultFieldsGroups maps the default fields to their group.
+var defaultFieldsGroups = map[Field]string{
+	FieldIP:        clientGroup,
+	FieldUserAgent: clientGroup,
+	FieldStatus:    httpGroup,
+	FieldMethod:    httpGroup,
+	FieldPath:      httpGroup,
+	FieldLatency:   httpGroup,
+	FieldBytesIn:   httpGroup,
+	FieldBytesOut:  httpGroup,
+	FieldRequestID: httpGroup,
+}
+
+// ecsFieldNames maps the default fields to the Elastic Common Schema fields.
+var ecsFieldNames = map[Field]string{
+	FieldIP:        "client.ip",
+	FieldStatus:    "http.response.status_code",
+	FieldMethod:    "http.request.method",
+	FieldPath:      "url.path",
+	FieldUserAgent: "user_agent.original",
+	FieldLatency:   "event.duration",
+	FieldBytesIn:   "http.request.body.bytes",
+	FieldBytesOut:  "http.response.body.bytes",
+	FieldRequestID: "http.request.id",
+}
+
+// otelFieldNames maps the default fields to the OpenTelemetry HTTP semantic
+// conventions attributes.
+var otelFieldNames = map[Field]string{
+	FieldIP:        "client.address",
+	FieldStatus:    "http.response.status_code",
+	FieldMethod:    "http.request.method",
+	FieldPath:      "url.path",
+	FieldUserAgent: "user_agent.original",
+	FieldLatency:   "http.server.request.duration",
+	FieldBytesIn:   "http.request.body.size",
+	FieldBytesOut:  "http.response.body.size",
+	FieldRequestID: "http.request.header.x-request-id",
+}
+
+// formatDefaultFields returns the default fields renamed and, if enabled,
+// grouped under the client and http groups. Empty groups are skipped.
+func (c *Config) formatDefaultFields(attributes []slog.Attr) []slog.Attr {
+	if len(c.fieldNames) == 0 && !c.groups {
+		return attributes
+	}
+
+	groups := map[string][]any{}
+	for i, attr := range attributes {
+		group := defaultFieldsGroups[Field(attr.Key)]
+		if name, ok := c.fieldNames[Field(attr.Key)]; ok {
+			attr.Key = name
+			attributes[i] = attr
+		}
+		groups[group] = append(groups[group], attr)
+	}
+	if !c.groups {
+		return attributes
+	}
+
+	grouped := make([]slog.Attr, 0, 2)
+	for _, group := range []string{clientGroup, httpGroup} {
+		if len(groups[group]) > 0 {
+			grouped = append(grouped, slog.Group(group, groups[group]...))
+		}
+	}
+	return grouped
+}
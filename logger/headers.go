@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/FabienMht/ginslog/redact"
+)
+
+// canonicalHeaders returns the canonical format of the headers names.
+func canonicalHeaders(headers []string) []string {
+	canonical := make([]string, 0, len(headers))
+	for _, h := range headers {
+		canonical = append(canonical, http.CanonicalHeaderKey(h))
+	}
+	return canonical
+}
+
+// headersGroup returns a group with the given headers, the sensitive headers
+// values are redacted. Missing headers are skipped.
+// It returns false if no headers are found.
+func (c *Config) headersGroup(key string, header http.Header, names []string) (slog.Attr, bool) {
+	attributes := make([]any, 0, len(names))
+	for _, name := range names {
+		values := header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		value := strings.Join(values, ", ")
+		if _, ok := c.unredactedHeaders[name]; !ok && c.redactor.IsSensitiveHeader(name) {
+			value = redact.Redacted
+		}
+		attributes = append(attributes, slog.String(strings.ToLower(name), value))
+	}
+	if len(attributes) == 0 {
+		return slog.Attr{}, false
+	}
+	return slog.Group(key, attributes...), true
+}
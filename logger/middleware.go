@@ -2,11 +2,17 @@ package logger
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"log/slog"
+	"net"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/FabienMht/ginslog/internal/httpbody"
+	"github.com/FabienMht/ginslog/requestid"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 // New returns a gin.HandlerFunc (middleware) that logs requests using slog.
@@ -28,58 +34,249 @@ import (
 //   - Path
 //   - User agent
 //   - Latency
+//   - Request body size
+//   - Response body size
 //   - Request ID (X-Request-ID header)
+//
+// If the requestid middleware is mounted before, its request ID is logged.
+// Otherwise, a request ID is generated and stored in the gin context and the
+// request context, it can be retrieved by the handlers with GetRequestID.
+//
+// A request logger logging the request ID, the route and the client IP address
+// is stored in the gin context, it can be retrieved by the handlers with
+// FromContext.
 func New(logger *slog.Logger, opts ...ConfigOption) gin.HandlerFunc {
-	config := newConfig()
-	for _, opt := range opts {
-		opt(config)
-	}
+	return NewFromConfig(logger, NewConfig(opts...))
+}
+
+// NewFromConfig returns a gin.HandlerFunc (middleware) that logs requests
+// using slog with the given Config, see New and NewConfig.
+func NewFromConfig(logger *slog.Logger, config *Config) gin.HandlerFunc {
 	config.validate()
 
+	// Request ID middleware used when the requestid middleware is not mounted
+	requestIDOptions := slices.Clone(config.requestIDOptions)
+	if !config.requestIDField {
+		requestIDOptions = append(requestIDOptions, requestid.WithoutResponseHeader())
+	}
+	setRequestID := requestid.New(requestIDOptions...)
+
 	return func(c *gin.Context) {
 		start := time.Now()
-		requestID := uuid.New().String()
 
-		if config.requestIDField {
-			c.Header("X-Request-ID", requestID)
+		// Check if the path, the user agent or the headers are excluded by the
+		// whitelist, the blacklists or the header filters before doing any work,
+		// unless malformed requests must be logged
+		path := config.normalizePath(c.Request.URL.Path)
+		excluded := config.isExcludedPath(path) ||
+			config.isExcludedUserAgent(c.Request.UserAgent()) ||
+			config.isExcludedHeader(c.Request.Header) ||
+			config.isHealthCheck(path, c.Request.UserAgent()) ||
+			(config.control != nil && config.control.isExcludedPath(path))
+		if excluded && !config.malformedRequests {
+			// Store the middleware logger, so the handlers log with the same handler
+			c.Set(loggerKey, logger)
+			return
+		}
+
+		// Set the request ID if not already set by the requestid middleware
+		requestID := requestid.Get(c)
+		if requestID == "" {
+			setRequestID(c)
+			requestID = requestid.Get(c)
+		}
+
+		// Store the request logger in the gin context, it is built on first use
+		if excluded {
+			c.Set(loggerKey, logger)
+		} else {
+			c.Set(loggerKey, &requestLogger{parent: logger, config: config, requestID: requestID})
+		}
+
+		// Log the request start
+		if config.requestStartLog && !excluded {
+			logger.LogAttrs(config.logContext(c), config.requestStartLevel, "Request started", config.formatDefaultFields([]slog.Attr{
+				slog.String("method", c.Request.Method),
+				slog.String("path", config.redactor.Path(path)),
+				slog.String("request-id", requestID),
+			})...)
+		}
+
+		// Log the long-lived connection opening and count the bytes exchanged
+		// on the hijacked connection
+		var stream *hijackWriter
+		if config.longLivedConnections && isLongLived(c.Request) {
+			stream = &hijackWriter{ResponseWriter: c.Writer}
+			c.Writer = stream
+			if !excluded {
+				logger.LogAttrs(config.logContext(c), slog.LevelInfo, "Connection opened", config.formatDefaultFields([]slog.Attr{
+					slog.String("ip", config.anonymizeIP(c.ClientIP())),
+					slog.String("method", c.Request.Method),
+					slog.String("path", config.redactor.Path(path)),
+					slog.String("request-id", requestID),
+				})...)
+			}
+		}
+
+		// Capture the request body
+		// Binary, multipart and compressed bodies are not read
+		var requestBody []byte
+		var requestBodyTruncated, requestBodyBinary bool
+		if config.requestBodyMaxSize > 0 && c.Request.Body != nil &&
+			httpbody.MatchContentType(c.ContentType(), config.requestBodyContentTypes) {
+			if httpbody.IsBinary(c.ContentType(), c.GetHeader("Content-Encoding")) {
+				requestBodyBinary = true
+			} else {
+				requestBody, c.Request.Body, requestBodyTruncated, _ = httpbody.Peek(c.Request.Body, config.requestBodyMaxSize) //nolint: errcheck
+			}
+		}
+
+		// Compute the request body digest
+		var digest *digestReader
+		if config.requestDigestMaxSize > 0 && c.Request.Body != nil {
+			digest = newDigestReader(c.Request.Body)
+			c.Request.Body = digest
+		}
+
+		// Count the request body bytes read
+		var body *countingReader
+		if config.bytesInField && config.bytesInRead && c.Request.Body != nil {
+			body = &countingReader{ReadCloser: c.Request.Body}
+			c.Request.Body = body
+		}
+
+		// Wrap the response writer to record the time to first byte
+		// and capture the response body
+		var writer *responseWriter
+		if config.ttfbField || config.responseBodyMaxSize > 0 {
+			writer = newResponseWriter(c.Writer, start, config.responseBodyMaxSize, config.isBodyStatus)
+			c.Writer = writer
 		}
 
 		// Process the request
 		c.Next()
+		latency := time.Since(start)
 
-		// Check if the path is whitelisted
-		if len(config.whitelistPaths) > 0 {
-			for _, v := range config.whitelistPaths {
-				if !v.MatchString(c.Request.URL.Path) {
-					return
-				}
+		// Check if the client went away
+		aborted := config.abortedField && errors.Is(c.Request.Context().Err(), context.Canceled)
+
+		// Restore the response writers
+		if writer != nil {
+			c.Writer = writer.ResponseWriter
+		}
+		if stream != nil {
+			c.Writer = stream.ResponseWriter
+		}
+
+		// Check if gin reported a bind (parse) error
+		var bindErrors []string
+		if config.malformedRequests {
+			bindErrors = c.Errors.ByType(gin.ErrorTypeBind).Errors()
+		}
+
+		// Malformed requests are always logged
+		if len(bindErrors) == 0 {
+			// Check if the path is excluded
+			if excluded {
+				return
+			}
+
+			// Check if the request should be logged
+			if config.customFilter != nil && !config.customFilter(c) {
+				return
+			}
+
+			// Check if the request is skipped
+			if config.isSkipped(c) {
+				return
+			}
+		}
+
+		// Record the request in the summary
+		if config.summarizer != nil {
+			route := c.FullPath()
+			if route == "" {
+				route = unmatchedRoute
+			}
+			logSummary(logger, config.summarizer.record(route, c.Writer.Status(), latency, time.Now()))
+			if !config.summarizer.requests {
+				return
 			}
 		}
 
-		// Check if the path is blacklisted
-		if len(config.blacklistPaths) > 0 {
-			for _, v := range config.blacklistPaths {
-				if v.MatchString(c.Request.URL.Path) {
+		// Sample the requests, malformed requests are always logged
+		sampleRate := 1.0
+		if len(bindErrors) == 0 {
+			// Check if the request is sampled out
+			if config.isSampledOut(c.Writer.Status()) ||
+				(config.control != nil && config.control.isSampledOut(c.Writer.Status())) {
+				return
+			}
+
+			// Check if the request is sampled out by the adaptive sampler
+			if config.adaptiveSampler != nil && c.Writer.Status() < 400 {
+				var sampled bool
+				sampled, sampleRate = config.adaptiveSampler.sample(time.Now())
+				if !sampled {
 					return
 				}
 			}
 		}
 
-		// Check if the request should be logged
-		if config.customFilter != nil && !config.customFilter(c) {
+		// Check if the client exceeds the log rate limit
+		if config.clientLimiter != nil {
+			allowed, suppressed := config.clientLimiter.allow(c.ClientIP(), time.Now())
+			for ip, count := range suppressed {
+				logger.LogAttrs(
+					context.Background(), slog.LevelWarn, "Log lines suppressed",
+					slog.String("ip", config.anonymizeIP(ip)),
+					slog.Int("suppressed", count),
+					slog.Duration("interval", config.clientLimiter.interval),
+				)
+			}
+			if !allowed {
+				return
+			}
+		}
+
+		// Compute the log level
+		// The long-lived connections duration is not a latency
+		var level slog.Level
+		if stream != nil {
+			level = config.level(c, 0)
+		} else {
+			level = config.level(c, latency)
+		}
+
+		// Check if the level is below the minimum level
+		if (config.minLevel != nil && level < config.minLevel.Level()) ||
+			(config.control != nil && config.control.isBelowLevel(level)) {
 			return
 		}
 
+		// Check if the log line is a duplicate
+		if config.deduplicator != nil {
+			first, expired := config.deduplicator.check(dedupKey{path: path, status: c.Writer.Status(), level: level}, time.Now())
+			config.logDuplicates(logger, expired)
+			if !first {
+				return
+			}
+		}
+
 		attributes := []slog.Attr{}
 
 		// Add the IP address
 		if config.ipField {
-			attributes = append(attributes, slog.String("ip", c.ClientIP()))
+			attributes = append(attributes, slog.String("ip", config.anonymizeIP(c.ClientIP())))
 		}
 
 		// Add the status code
 		if config.statusField {
-			attributes = append(attributes, slog.Int("status", c.Writer.Status()))
+			status := c.Writer.Status()
+			if aborted && config.abortedStatus != 0 {
+				status = config.abortedStatus
+			}
+			attributes = append(attributes, slog.Int("status", status))
 		}
 
 		// Add the HTTP method
@@ -89,17 +286,53 @@ func New(logger *slog.Logger, opts ...ConfigOption) gin.HandlerFunc {
 
 		// Add the path
 		if config.pathField {
-			attributes = append(attributes, slog.String("path", c.Request.URL.Path))
+			attributes = append(attributes, slog.String("path", config.redactor.Path(path)))
 		}
 
 		// Add the user agent
 		if config.userAgentField {
-			attributes = append(attributes, slog.String("user-agent", c.Request.UserAgent()))
+			if config.parseUserAgent {
+				attributes = append(attributes, userAgentGroup("user-agent", c.Request.UserAgent()))
+			} else {
+				attributes = append(attributes, slog.String("user-agent", c.Request.UserAgent()))
+			}
 		}
 
 		// Add the latency
 		if config.latencyField {
-			attributes = append(attributes, slog.Duration("latency", time.Since(start)))
+			key := "latency"
+			if stream != nil {
+				key = "duration"
+			}
+			switch {
+			case config.otelFields:
+				// The OpenTelemetry duration is a number of seconds
+				attributes = append(attributes, slog.Float64(key, latency.Seconds()))
+			case config.prettyLatency:
+				attributes = append(attributes, slog.String(key, latency.String()))
+			default:
+				attributes = append(attributes, slog.Duration(key, latency))
+			}
+		}
+
+		// Add the request body size
+		if config.bytesInField {
+			if stream != nil && stream.conn != nil {
+				attributes = append(attributes, slog.Int64("bytes-in", stream.conn.read.Load()))
+			} else if body != nil {
+				attributes = append(attributes, slog.Int64("bytes-in", body.n))
+			} else {
+				attributes = append(attributes, slog.Int64("bytes-in", max(c.Request.ContentLength, 0)))
+			}
+		}
+
+		// Add the response body size
+		if config.bytesOutField {
+			if stream != nil && stream.conn != nil {
+				attributes = append(attributes, slog.Int64("bytes-out", stream.conn.written.Load()))
+			} else {
+				attributes = append(attributes, slog.Int("bytes-out", max(c.Writer.Size(), 0)))
+			}
 		}
 
 		// Add the request ID
@@ -107,31 +340,257 @@ func New(logger *slog.Logger, opts ...ConfigOption) gin.HandlerFunc {
 			attributes = append(attributes, slog.String("request-id", requestID))
 		}
 
+		// Remove the errors only fields, then rename and group the default fields
+		attributes = config.formatDefaultFields(config.filterErrorsOnlyFields(attributes, c.Writer.Status()))
+
+		// Add the OpenTelemetry route and peer address
+		if config.otelFields {
+			peerAddress, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+			if err != nil {
+				peerAddress = c.Request.RemoteAddr
+			}
+			attributes = append(attributes,
+				slog.String("http.route", c.FullPath()),
+				slog.String("network.peer.address", config.anonymizeIP(peerAddress)),
+			)
+		}
+
+		// Index of the first optional field
+		optionalFieldsIndex := len(attributes)
+
+		// Add the route parameters
+		if config.routeParamsField && len(c.Params) > 0 {
+			params := make([]any, 0, len(c.Params))
+			for _, p := range c.Params {
+				params = append(params, slog.String(p.Key, p.Value))
+			}
+			attributes = append(attributes, slog.Group("params", params...))
+		}
+
+		// Add the host
+		if config.hostField {
+			attributes = append(attributes, slog.String("host", c.Request.Host))
+		}
+
+		// Add the referer
+		if config.refererField {
+			attributes = append(attributes, slog.String("referer", config.redactor.URL(c.Request.Referer())))
+		}
+
+		// Add the protocol version
+		if config.protoField {
+			attributes = append(attributes, slog.String("proto", c.Request.Proto))
+		}
+
+		// Add the TLS connection state
+		if config.tlsField && c.Request.TLS != nil {
+			attributes = append(attributes, slog.Group("tls",
+				slog.String("version", tls.VersionName(c.Request.TLS.Version)),
+				slog.String("cipher", tls.CipherSuiteName(c.Request.TLS.CipherSuite)),
+				slog.String("server-name", c.Request.TLS.ServerName),
+			))
+		}
+
+		// Add the request start time
+		if config.startTimeField {
+			startTime := start
+			if config.startTimeUTC {
+				startTime = startTime.UTC()
+			}
+			if config.startTimeLayout != "" {
+				attributes = append(attributes, slog.String("start-time", startTime.Format(config.startTimeLayout)))
+			} else {
+				attributes = append(attributes, slog.Time("start-time", startTime))
+			}
+		}
+
+		// Add the request headers
+		if len(config.requestHeaders) > 0 {
+			headers, ok := config.headersGroup("request-headers", c.Request.Header, config.requestHeaders)
+			if ok {
+				attributes = append(attributes, headers)
+			}
+		}
+
+		// Add the response headers
+		if len(config.responseHeaders) > 0 {
+			headers, ok := config.headersGroup("response-headers", c.Writer.Header(), config.responseHeaders)
+			if ok {
+				attributes = append(attributes, headers)
+			}
+		}
+
+		// Add the response content type
+		if config.contentTypeField {
+			attributes = append(attributes, slog.String("content-type", c.Writer.Header().Get("Content-Type")))
+		}
+
+		// Add the gin context errors
+		if config.errorsField && len(c.Errors) > 0 {
+			attributes = append(attributes, slog.String("errors", strings.Join(c.Errors.Errors(), "; ")))
+		}
+
+		// Add the authenticated user
+		if config.userExtractor != nil {
+			if user := config.userExtractor(c); user != "" {
+				attributes = append(attributes, slog.String("user", user))
+			}
+		}
+
+		// Add the remote address
+		if config.remoteAddrField {
+			attributes = append(attributes, slog.String("remote-addr", config.anonymizeAddr(c.Request.RemoteAddr)))
+		}
+
+		// Add the X-Forwarded-For chain
+		if config.forwardedForField {
+			if forwardedFor := c.Request.Header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+				chain := config.anonymizeIPList(strings.Join(forwardedFor, ", "))
+				attributes = append(attributes, slog.String("forwarded-for", chain))
+			}
+		}
+
+		// Add the handler name
+		if config.handlerField {
+			attributes = append(attributes, slog.String("handler", c.HandlerName()))
+		}
+
+		// Add the cookies names
+		if config.cookiesField {
+			if cookies := c.Request.Cookies(); len(cookies) > 0 {
+				names := make([]string, 0, len(cookies))
+				for _, cookie := range cookies {
+					names = append(names, cookie.Name)
+				}
+				attributes = append(attributes, slog.String("cookies", strings.Join(names, ",")))
+			}
+		}
+
+		// Add the time to first byte
+		if config.ttfbField && writer != nil && writer.ttfb != 0 {
+			attributes = append(attributes, slog.Duration("ttfb", writer.ttfb))
+		}
+
+		// Add the request body
+		if config.isBodyStatus(c.Writer.Status()) {
+			if requestBodyBinary {
+				attributes = append(attributes, binaryBodyGroup("request-body", c.ContentType(), max(c.Request.ContentLength, 0)))
+			} else if len(requestBody) > 0 {
+				attributes = append(attributes, slog.String("request-body", httpbody.Format(requestBody, requestBodyTruncated)))
+			}
+		}
+
+		// Add the request body digest
+		if digest != nil {
+			if attr, ok := digest.digest(int64(config.requestDigestMaxSize)); ok {
+				attributes = append(attributes, attr)
+			}
+		}
+
+		// Add the response body
+		if writer != nil && httpbody.MatchContentType(c.Writer.Header().Get("Content-Type"), config.responseBodyContentTypes) {
+			if writer.bodyBinary {
+				attributes = append(attributes, binaryBodyGroup(
+					"response-body", c.Writer.Header().Get("Content-Type"), int64(max(c.Writer.Size(), 0)),
+				))
+			} else if writer.body.Len() > 0 {
+				attributes = append(attributes, slog.String("response-body", httpbody.Format(writer.body.Bytes(), writer.bodyTruncated)))
+			}
+		}
+
+		// Add the client IP address enrichment
+		if config.ipEnricher != nil {
+			attributes = append(attributes, config.ipEnricher(c.ClientIP())...)
+		}
+
+		// Add the client disconnect
+		if aborted {
+			attributes = append(attributes, slog.Bool("aborted", true))
+		}
+
+		// Add the request context deadline outcome
+		if config.deadlineField {
+			if deadline, ok := c.Request.Context().Deadline(); ok {
+				remaining := time.Until(deadline)
+				exceeded := remaining <= 0 || errors.Is(c.Request.Context().Err(), context.DeadlineExceeded)
+				attributes = append(attributes, slog.Group("deadline",
+					slog.Bool("exceeded", exceeded),
+					slog.Duration("remaining", remaining),
+				))
+			}
+		}
+
+		// Add the correlation ID
+		if config.correlationIDHeader != "" {
+			if correlationID := c.GetHeader(config.correlationIDHeader); correlationID != "" {
+				attributes = append(attributes, slog.String("correlation-id", correlationID))
+			}
+		}
+
+		// Add the request context values
+		for _, attr := range config.contextAttrs {
+			if value := c.Request.Context().Value(attr.key); value != nil {
+				attributes = append(attributes, slog.Any(attr.name, value))
+			}
+		}
+
+		// Add the effective sampling rate
+		if config.adaptiveSampler != nil {
+			attributes = append(attributes, slog.Float64("sample-rate", sampleRate))
+		}
+
+		// Add the bind errors
+		if len(bindErrors) > 0 {
+			attributes = append(attributes, slog.String("malformed", strings.Join(bindErrors, "; ")))
+		}
+
+		// Remove the errors only optional fields
+		attributes = append(
+			attributes[:optionalFieldsIndex],
+			config.filterErrorsOnlyFields(attributes[optionalFieldsIndex:], c.Writer.Status())...,
+		)
+
+		// Add the static fields
+		attributes = append(attributes, config.staticAttrs...)
+
 		// Add custom fields
 		if config.customFields != nil {
 			attributes = append(attributes, config.customFields(c)...)
 		}
 
-		// Log according to the status code
-		for _, httpLevel := range config.httpLevels {
-			if httpLevel.match(c.Writer.Status()) {
-				logger.LogAttrs(context.Background(), httpLevel.level, "Incoming request", attributes...)
+		// Call the attributes hook
+		if config.attrHook != nil {
+			attributes = config.attrHook(c, attributes)
+		}
 
-				// Call the custom logger
-				if config.customLogger != nil {
-					config.customLogger(c, logger)
-				}
+		// Build the pending log line
+		record := &Record{Level: level, Message: config.messageFunc(c), Attrs: attributes}
+		if stream != nil {
+			record.Message = "Connection closed"
+		}
 
+		// Call the pre-log hooks, they can modify or cancel the log line
+		for _, beforeLog := range config.beforeLog {
+			if !beforeLog(c, record) {
 				return
 			}
 		}
 
-		// If no status code matched, use the default level
-		logger.LogAttrs(context.Background(), config.defaultLevel, "Incoming request", attributes...)
+		// Log according to the path, the status code and the latency
+		requestLogger := logger
+		if config.errorLogger != nil && c.Writer.Status() >= 500 {
+			requestLogger = config.errorLogger
+		}
+		requestLogger.LogAttrs(config.logContext(c), record.Level, record.Message, record.Attrs...)
 
-		// Call the custom logger
+		// Call the custom logger, if set, after the log line is emitted
 		if config.customLogger != nil {
-			config.customLogger(c, logger)
+			config.customLogger(c, requestLogger, record.Level, record.Message, record.Attrs)
+		}
+
+		// Call the post-log hooks
+		for _, onLog := range config.onLog {
+			onLog(c, record.Level, record.Attrs)
 		}
 	}
 }
@@ -1,13 +1,28 @@
 package logger
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
+	"github.com/FabienMht/ginslog/redact"
+	"github.com/FabienMht/ginslog/requestid"
 	"github.com/FabienMht/ginslog/slogtest"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -36,6 +51,8 @@ func TestNew(t *testing.T) {
 				slog.String("path", "/test"),
 				slog.String("user-agent", "test"),
 				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
 				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 			},
 			wantLevel: slog.LevelInfo,
@@ -53,6 +70,8 @@ func TestNew(t *testing.T) {
 				slog.String("path", "/test"),
 				slog.String("user-agent", "test"),
 				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
 				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 			},
 			wantLevel: slog.LevelWarn,
@@ -67,6 +86,8 @@ func TestNew(t *testing.T) {
 				slog.String("path", "/test"),
 				slog.String("user-agent", "test"),
 				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
 				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 			},
 			wantLevel: slog.LevelInfo,
@@ -81,6 +102,8 @@ func TestNew(t *testing.T) {
 				slog.String("path", "/test"),
 				slog.String("user-agent", "test"),
 				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
 				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 			},
 			wantLevel: slog.LevelInfo,
@@ -95,6 +118,8 @@ func TestNew(t *testing.T) {
 				slog.String("method", "GET"),
 				slog.String("user-agent", "test"),
 				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
 				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 			},
 			wantLevel: slog.LevelInfo,
@@ -109,6 +134,8 @@ func TestNew(t *testing.T) {
 				slog.String("method", "GET"),
 				slog.String("path", "/test"),
 				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
 				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 			},
 			wantLevel: slog.LevelInfo,
@@ -123,6 +150,40 @@ func TestNew(t *testing.T) {
 				slog.String("method", "GET"),
 				slog.String("path", "/test"),
 				slog.String("user-agent", "test"),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "without bytes in",
+			opts: []ConfigOption{WithoutBytesIn()},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test"),
+				slog.String("user-agent", "test"),
+				slog.String("latency", ""),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "without bytes out",
+			opts: []ConfigOption{WithoutBytesOut()},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test"),
+				slog.String("user-agent", "test"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
 				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 			},
 			wantLevel: slog.LevelInfo,
@@ -138,6 +199,26 @@ func TestNew(t *testing.T) {
 				slog.String("path", "/test"),
 				slog.String("user-agent", "test"),
 				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "with correlation ID",
+			opts: []ConfigOption{WithCorrelationID(HeaderCorrelationID)},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test"),
+				slog.String("user-agent", "test"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+				slog.String("correlation-id", "test"),
 			},
 			wantLevel: slog.LevelInfo,
 		},
@@ -165,11 +246,199 @@ func TestNew(t *testing.T) {
 				slog.String("path", "/test"),
 				slog.String("user-agent", "test"),
 				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
 				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 				slog.String("content-type", "test"),
 			},
 			wantLevel: slog.LevelInfo,
 		},
+		{
+			name: "with groups",
+			opts: []ConfigOption{WithGroups(), WithoutIP(), WithoutLatency()},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.Group("client",
+					slog.String("user-agent", "test"),
+				),
+				slog.Group("http",
+					slog.Int("status", 200),
+					slog.String("method", "GET"),
+					slog.String("path", "/test"),
+					slog.Int64("bytes-in", 0),
+					slog.Int("bytes-out", 4),
+					slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+				),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "with groups without client fields",
+			opts: []ConfigOption{WithGroups(), WithoutIP(), WithoutUserAgent(), WithoutLatency()},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.Group("http",
+					slog.Int("status", 200),
+					slog.String("method", "GET"),
+					slog.String("path", "/test"),
+					slog.Int64("bytes-in", 0),
+					slog.Int("bytes-out", 4),
+					slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+				),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "with ECS fields",
+			opts: []ConfigOption{WithGroups(), WithECSFields(), WithoutIP(), WithoutLatency()},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.Int("http.response.status_code", 200),
+				slog.String("http.request.method", "GET"),
+				slog.String("url.path", "/test"),
+				slog.String("user_agent.original", "test"),
+				slog.Int64("http.request.body.bytes", 0),
+				slog.Int("http.response.body.bytes", 4),
+				slog.String("http.request.id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "with OpenTelemetry semantic conventions",
+			opts: []ConfigOption{WithOTelSemConv(), WithoutIP(), WithoutLatency()},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.Int("http.response.status_code", 200),
+				slog.String("http.request.method", "GET"),
+				slog.String("url.path", "/test"),
+				slog.String("user_agent.original", "test"),
+				slog.Int64("http.request.body.size", 0),
+				slog.Int("http.response.body.size", 4),
+				slog.String("http.request.header.x-request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+				slog.String("http.route", "/test"),
+				slog.String("network.peer.address", "192.0.2.1"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "with field names",
+			opts: []ConfigOption{
+				WithFieldNames(map[Field]string{FieldStatus: "code", FieldUserAgent: "ua"}),
+				WithoutIP(),
+				WithoutLatency(),
+			},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.Int("code", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test"),
+				slog.String("ua", "test"),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "with field names with groups",
+			opts: []ConfigOption{
+				WithGroups(),
+				WithFieldNames(map[Field]string{FieldStatus: "code", FieldUserAgent: "ua"}),
+				WithoutIP(),
+				WithoutLatency(),
+			},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.Group("client",
+					slog.String("ua", "test"),
+				),
+				slog.Group("http",
+					slog.Int("code", 200),
+					slog.String("method", "GET"),
+					slog.String("path", "/test"),
+					slog.Int64("bytes-in", 0),
+					slog.Int("bytes-out", 4),
+					slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+				),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "with static attributes",
+			opts: []ConfigOption{
+				WithoutDefaultFields(),
+				WithStaticAttrs([]slog.Attr{slog.String("service", "api"), slog.String("version", "1.0.0")}),
+				WithStaticAttrs([]slog.Attr{slog.String("env", "prod")}),
+			},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("service", "api"),
+				slog.String("version", "1.0.0"),
+				slog.String("env", "prod"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "with errors only fields on success",
+			opts: []ConfigOption{WithContentType(), WithErrorsOnlyFields(400, []string{"user-agent", "content-type"})},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "with errors only fields on error",
+			opts: []ConfigOption{WithContentType(), WithErrorsOnlyFields(400, []string{"user-agent", "content-type"})},
+			code: 500,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 500),
+				slog.String("method", "GET"),
+				slog.String("path", "/test"),
+				slog.String("user-agent", "test"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+				slog.String("content-type", "application/json; charset=utf-8"),
+			},
+			wantLevel: slog.LevelError,
+		},
+		{
+			name: "with attributes hook",
+			opts: []ConfigOption{
+				WithAttrHook(func(c *gin.Context, attrs []slog.Attr) []slog.Attr {
+					hooked := []slog.Attr{}
+					for _, attr := range attrs {
+						switch attr.Key {
+						case "ip", "latency", "bytes-in", "bytes-out", "request-id":
+						case "path":
+							hooked = append(hooked, slog.String("path", strings.ToUpper(attr.Value.String())))
+						default:
+							hooked = append(hooked, attr)
+						}
+					}
+					return append(hooked, slog.Bool("success", c.Writer.Status() < 400))
+				}),
+			},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/TEST"),
+				slog.String("user-agent", "test"),
+				slog.Bool("success", true),
+			},
+			wantLevel: slog.LevelInfo,
+		},
 		{
 			name: "custom without default fields",
 			opts: []ConfigOption{
@@ -219,6 +488,8 @@ func TestNew(t *testing.T) {
 			req, err := http.NewRequest("GET", "/test", nil)
 			req.Header.Set("User-Agent", "test")
 			req.Header.Set("Content-Type", "test")
+			req.Header.Set("X-Correlation-ID", "test")
+			req.RemoteAddr = "192.0.2.1:1234"
 			require.NoError(t, err)
 			router.ServeHTTP(resp, req)
 		})
@@ -236,7 +507,24 @@ func TestNewWhiteListBlackList(t *testing.T) {
 	}{
 		{
 			name: "whitelist test1",
-			opts: []ConfigOption{WithBlacklistPath([]string{"/test1"})},
+			opts: []ConfigOption{WithWhitelistPath([]string{"/test1"})},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test1"),
+				slog.String("user-agent", "test1"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "whitelist test1 and test3",
+			opts: []ConfigOption{WithWhitelistPath([]string{"/test1", "/test3"})},
 			code: 200,
 			wantFields: []slog.Attr{
 				slog.String("ip", ""),
@@ -245,7 +533,9 @@ func TestNewWhiteListBlackList(t *testing.T) {
 				slog.String("path", "/test1"),
 				slog.String("user-agent", "test1"),
 				slog.String("latency", ""),
-				slog.String("request-id", "9566c74d-1003-4c4d-bbbb-0407d1e2c649"),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 			},
 			wantLevel: slog.LevelInfo,
 		},
@@ -260,26 +550,161 @@ func TestNewWhiteListBlackList(t *testing.T) {
 				slog.String("path", "/test2"),
 				slog.String("user-agent", "test2"),
 				slog.String("latency", ""),
-				slog.String("request-id", "9566c74d-1003-4c4d-bbbb-0407d1e2c649"),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 			},
 			wantLevel: slog.LevelInfo,
 		},
 		{
-			name: "whitelist and blacklist test1",
-			opts: []ConfigOption{
-				WithWhitelistPath([]string{"/test1"}),
-				WithBlacklistPath([]string{"/test1"}),
+			name: "exact whitelist test1",
+			opts: []ConfigOption{WithWhitelistPathExact([]string{"/test1", "/test3"})},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test1"),
+				slog.String("user-agent", "test1"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "exact blacklist test1",
+			opts: []ConfigOption{WithBlacklistPathExact([]string{"/test1"})},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test2"),
+				slog.String("user-agent", "test2"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "glob whitelist test1",
+			opts: []ConfigOption{WithWhitelistPathGlob([]string{"/*1"})},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test1"),
+				slog.String("user-agent", "test1"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "glob blacklist test1",
+			opts: []ConfigOption{WithBlacklistPathGlob([]string{"/test[13]"})},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test2"),
+				slog.String("user-agent", "test2"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name:       "invalid glob",
+			opts:       []ConfigOption{WithBlacklistPathGlob([]string{"/test["})},
 			code:       200,
 			wantFields: []slog.Attr{},
 			wantLevel:  slog.LevelInfo,
 			wantPanic:  true,
 		},
 		{
-			name: "filter test2",
+			name: "blacklist user agent test1",
+			opts: []ConfigOption{WithBlacklistUserAgents([]string{"^test1$", "^bot"})},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test2"),
+				slog.String("user-agent", "test2"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "include header test1",
+			opts: []ConfigOption{WithHeaderFilter("user-agent", "^test1$", true)},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test1"),
+				slog.String("user-agent", "test1"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "exclude header test1",
+			opts: []ConfigOption{WithHeaderFilter("User-Agent", "^test1$", false)},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test2"),
+				slog.String("user-agent", "test2"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "exclude missing header",
+			opts: []ConfigOption{WithHeaderFilter("X-Internal", "^true$", false), WithWhitelistPath([]string{"/test1"})},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test1"),
+				slog.String("user-agent", "test1"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "skipper test2",
 			opts: []ConfigOption{
-				WithCustomFilter(func(c *gin.Context) bool {
-					return c.Request.URL.Path != "/test2"
+				WithSkipper(func(c *gin.Context) bool {
+					return c.Request.UserAgent() == "test2"
 				}),
 			},
 			code: 200,
@@ -290,6 +715,40 @@ func TestNewWhiteListBlackList(t *testing.T) {
 				slog.String("path", "/test1"),
 				slog.String("user-agent", "test1"),
 				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+			},
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "whitelist and blacklist test1",
+			opts: []ConfigOption{
+				WithWhitelistPath([]string{"/test1"}),
+				WithBlacklistPath([]string{"/test1"}),
+			},
+			code:       200,
+			wantFields: []slog.Attr{},
+			wantLevel:  slog.LevelInfo,
+			wantPanic:  true,
+		},
+		{
+			name: "filter test2",
+			opts: []ConfigOption{
+				WithCustomFilter(func(c *gin.Context) bool {
+					return c.Request.URL.Path != "/test2"
+				}),
+			},
+			code: 200,
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 200),
+				slog.String("method", "GET"),
+				slog.String("path", "/test1"),
+				slog.String("user-agent", "test1"),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 4),
 				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
 			},
 			wantLevel: slog.LevelInfo,
@@ -337,3 +796,3368 @@ func TestNewWhiteListBlackList(t *testing.T) {
 		})
 	}
 }
+
+func TestNewMalformedRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		wantFields  []slog.Attr
+		wantRecords int
+	}{
+		{
+			name:        "without malformed requests",
+			opts:        []ConfigOption{WithBlacklistPath([]string{"/test"})},
+			wantFields:  []slog.Attr{},
+			wantRecords: 0,
+		},
+		{
+			name: "with malformed requests",
+			opts: []ConfigOption{
+				WithBlacklistPath([]string{"/test"}),
+				WithMalformedRequests(),
+			},
+			wantFields: []slog.Attr{
+				slog.String("ip", ""),
+				slog.Int("status", 400),
+				slog.String("method", "GET"),
+				slog.String("path", "/test"),
+				slog.String("user-agent", ""),
+				slog.String("latency", ""),
+				slog.Int64("bytes-in", 0),
+				slog.Int("bytes-out", 0),
+				slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+				slog.String("malformed", "invalid body"),
+			},
+			wantRecords: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Set a fixed random seed to get a fixed request ID
+			uuid.SetRand(rand.New(rand.NewSource(1)))
+
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelWarn,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), tt.opts...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				_ = c.Error(errors.New("invalid body")).SetType(gin.ErrorTypeBind)
+				c.AbortWithStatus(http.StatusBadRequest)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, tt.wantRecords, handler.Records())
+		})
+	}
+}
+
+func TestNewServerErrorLog(t *testing.T) {
+	// Create a new logger with a mock handler
+	handler := slogtest.NewMockHandler(
+		slog.NewTextHandler(os.Stderr, nil),
+		t,
+		slog.LevelWarn,
+		[]slog.Attr{slog.String("error", "http: TLS handshake error from 127.0.0.1:1234: EOF")},
+		[]string{},
+	)
+
+	errorLog := NewServerErrorLog(slog.New(handler), slog.LevelWarn)
+	errorLog.Printf("http: TLS handshake error from %s: %v", "127.0.0.1:1234", io.EOF)
+
+	// Check the number of log lines
+	require.Equal(t, 1, handler.Records())
+}
+
+func TestNewRequestID(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		middlewares []gin.HandlerFunc
+		requestID   string
+		want        string
+	}{
+		{
+			name:      "default options",
+			opts:      []ConfigOption{},
+			requestID: "client-id",
+			want:      "52fdfc07-2182-454f-963f-5f0f9a621d72",
+		},
+		{
+			name:      "trust valid request ID",
+			opts:      []ConfigOption{WithTrustRequestID()},
+			requestID: "client-id",
+			want:      "client-id",
+		},
+		{
+			name:      "trust invalid request ID",
+			opts:      []ConfigOption{WithTrustRequestID()},
+			requestID: "client id\n",
+			want:      "52fdfc07-2182-454f-963f-5f0f9a621d72",
+		},
+		{
+			name:      "trust missing request ID",
+			opts:      []ConfigOption{WithTrustRequestID()},
+			requestID: "",
+			want:      "52fdfc07-2182-454f-963f-5f0f9a621d72",
+		},
+		{
+			name:      "blacklisted path",
+			opts:      []ConfigOption{WithBlacklistPath([]string{"/test"})},
+			requestID: "",
+			want:      "",
+		},
+		{
+			name: "request ID options",
+			opts: []ConfigOption{
+				WithRequestIDOptions(requestid.WithGenerator(func() string { return "generated-id" })),
+			},
+			requestID: "",
+			want:      "generated-id",
+		},
+		{
+			name: "requestid middleware",
+			opts: []ConfigOption{},
+			middlewares: []gin.HandlerFunc{
+				requestid.New(requestid.WithGenerator(func() string { return "middleware-id" })),
+			},
+			requestID: "",
+			want:      "middleware-id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Set a fixed random seed to get a fixed request ID
+			uuid.SetRand(rand.New(rand.NewSource(1)))
+
+			// Create a new logger with a mock handler
+			logger := slog.New(slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				[]slog.Attr{
+					slog.String("ip", ""),
+					slog.Int("status", 200),
+					slog.String("method", "GET"),
+					slog.String("path", "/test"),
+					slog.String("user-agent", ""),
+					slog.String("latency", ""),
+					slog.Int64("bytes-in", 0),
+					slog.Int("bytes-out", 4),
+					slog.String("request-id", tt.want),
+				},
+				skipFields,
+			))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(tt.middlewares...)
+			router.Use(New(logger, tt.opts...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				require.Equal(t, tt.want, GetRequestID(c))
+				c.JSON(200, nil)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			req.Header.Set("X-Request-ID", tt.requestID)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the response header
+			require.Equal(t, tt.want, resp.Header().Get("X-Request-ID"))
+		})
+	}
+}
+
+func TestNewBytesIn(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ConfigOption
+		read      int64
+		wantBytes int64
+	}{
+		{
+			name:      "content length",
+			opts:      []ConfigOption{},
+			read:      2,
+			wantBytes: 5,
+		},
+		{
+			name:      "bytes read",
+			opts:      []ConfigOption{WithBytesInRead()},
+			read:      2,
+			wantBytes: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Set a fixed random seed to get a fixed request ID
+			uuid.SetRand(rand.New(rand.NewSource(1)))
+
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				[]slog.Attr{
+					slog.String("ip", ""),
+					slog.Int("status", 200),
+					slog.String("method", "POST"),
+					slog.String("path", "/test"),
+					slog.String("user-agent", ""),
+					slog.String("latency", ""),
+					slog.Int64("bytes-in", tt.wantBytes),
+					slog.Int("bytes-out", 4),
+					slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+				},
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), tt.opts...))
+
+			// Define routes
+			router.POST("/test", func(c *gin.Context) {
+				_, err := io.CopyN(io.Discard, c.Request.Body, tt.read)
+				require.NoError(t, err)
+				c.JSON(200, nil)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/test", strings.NewReader("hello"))
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, 1, handler.Records())
+		})
+	}
+}
+
+// optionalFieldsHandler is a named handler to get a fixed handler name.
+func optionalFieldsHandler(c *gin.Context) {
+	c.Set("user-id", "42")
+	c.Set(gin.AuthUserKey, "admin")
+	c.Header("X-RateLimit-Remaining", "10")
+	c.SetCookie("session", "secret", 0, "/", "", true, true)
+	_ = c.Error(errors.New("first error"))
+	_ = c.Error(errors.New("second error"))
+	c.JSON(200, nil)
+}
+
+func TestNewOptionalFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []ConfigOption
+		wantFields []slog.Attr
+	}{
+		{
+			name: "with route params",
+			opts: []ConfigOption{WithRouteParams()},
+			wantFields: []slog.Attr{
+				slog.Group("params", slog.String("id", "1"), slog.String("name", "test")),
+			},
+		},
+		{
+			name: "with host",
+			opts: []ConfigOption{WithHost()},
+			wantFields: []slog.Attr{
+				slog.String("host", "example.com"),
+			},
+		},
+		{
+			name: "with referer",
+			opts: []ConfigOption{WithReferer()},
+			wantFields: []slog.Attr{
+				slog.String("referer", "https://example.org/"),
+			},
+		},
+		{
+			name: "with proto",
+			opts: []ConfigOption{WithProto()},
+			wantFields: []slog.Attr{
+				slog.String("proto", "HTTP/1.1"),
+			},
+		},
+		{
+			name: "with TLS",
+			opts: []ConfigOption{WithTLS()},
+			wantFields: []slog.Attr{
+				slog.Group("tls",
+					slog.String("version", "TLS 1.3"),
+					slog.String("cipher", "TLS_AES_128_GCM_SHA256"),
+					slog.String("server-name", "example.com"),
+				),
+			},
+		},
+		{
+			name: "with start time",
+			opts: []ConfigOption{WithStartTime("MST"), WithStartTimeUTC()},
+			wantFields: []slog.Attr{
+				slog.String("start-time", "UTC"),
+			},
+		},
+		{
+			name: "with request headers",
+			opts: []ConfigOption{WithRequestHeaders([]string{"accept", "X-Api-Version", "Authorization", "Cookie", "X-Api-Key", "X-Missing"})},
+			wantFields: []slog.Attr{
+				slog.Group("request-headers",
+					slog.String("accept", "application/json, text/plain"),
+					slog.String("x-api-version", "2"),
+					slog.String("authorization", "[REDACTED]"),
+					slog.String("cookie", "[REDACTED]"),
+					slog.String("x-api-key", "[REDACTED]"),
+				),
+			},
+		},
+		{
+			name: "with unredacted request headers",
+			opts: []ConfigOption{
+				WithRequestHeaders([]string{"Authorization", "Cookie"}),
+				WithUnredactedHeaders([]string{"authorization"}),
+			},
+			wantFields: []slog.Attr{
+				slog.Group("request-headers",
+					slog.String("authorization", "Bearer token"),
+					slog.String("cookie", "[REDACTED]"),
+				),
+			},
+		},
+		{
+			name: "with redactor",
+			opts: []ConfigOption{
+				WithRequestHeaders([]string{"Authorization", "X-Api-Key", "X-Api-Version"}),
+				WithRedactor(redact.New(redact.WithoutDefaultHeaders(), redact.WithHeaderPatterns([]string{"(?i)^x-api-"}))),
+			},
+			wantFields: []slog.Attr{
+				slog.Group("request-headers",
+					slog.String("authorization", "Bearer token"),
+					slog.String("x-api-key", "[REDACTED]"),
+					slog.String("x-api-version", "[REDACTED]"),
+				),
+			},
+		},
+		{
+			name: "with response headers",
+			opts: []ConfigOption{WithResponseHeaders([]string{"Content-Type", "x-ratelimit-remaining", "Set-Cookie"})},
+			wantFields: []slog.Attr{
+				slog.Group("response-headers",
+					slog.String("content-type", "application/json; charset=utf-8"),
+					slog.String("x-ratelimit-remaining", "10"),
+					slog.String("set-cookie", "[REDACTED]"),
+				),
+			},
+		},
+		{
+			name: "with content type",
+			opts: []ConfigOption{WithContentType()},
+			wantFields: []slog.Attr{
+				slog.String("content-type", "application/json; charset=utf-8"),
+			},
+		},
+		{
+			name: "with errors",
+			opts: []ConfigOption{WithErrors()},
+			wantFields: []slog.Attr{
+				slog.String("errors", "first error; second error"),
+			},
+		},
+		{
+			name: "with user from basic auth",
+			opts: []ConfigOption{WithUser(UserFromBasicAuth)},
+			wantFields: []slog.Attr{
+				slog.String("user", "admin"),
+			},
+		},
+		{
+			name: "with user from context key",
+			opts: []ConfigOption{WithUser(UserFromContextKey("user-id"))},
+			wantFields: []slog.Attr{
+				slog.String("user", "42"),
+			},
+		},
+		{
+			name: "with remote address",
+			opts: []ConfigOption{WithRemoteAddr()},
+			wantFields: []slog.Attr{
+				slog.String("remote-addr", "192.0.2.1:1234"),
+			},
+		},
+		{
+			name: "with forwarded for",
+			opts: []ConfigOption{WithForwardedFor()},
+			wantFields: []slog.Attr{
+				slog.String("forwarded-for", "203.0.113.1, 198.51.100.1, 10.0.0.1"),
+			},
+		},
+		{
+			name: "with handler name",
+			opts: []ConfigOption{WithHandlerName()},
+			wantFields: []slog.Attr{
+				slog.String("handler", "github.com/FabienMht/ginslog/logger.optionalFieldsHandler"),
+			},
+		},
+		{
+			name: "with cookies",
+			opts: []ConfigOption{WithCookies()},
+			wantFields: []slog.Attr{
+				slog.String("cookies", "session,consent"),
+			},
+		},
+		{
+			name: "with TTFB",
+			opts: []ConfigOption{WithTTFB()},
+			wantFields: []slog.Attr{
+				slog.Duration("ttfb", 0),
+			},
+		},
+		{
+			name: "with IP enricher",
+			opts: []ConfigOption{
+				WithIPEnricher(func(ip string) []slog.Attr {
+					return []slog.Attr{slog.String("country", "FR"), slog.String("client-ip", ip)}
+				}),
+			},
+			wantFields: []slog.Attr{
+				slog.String("country", "FR"),
+				slog.String("client-ip", "203.0.113.1"),
+			},
+		},
+		{
+			name: "with truncated IP",
+			opts: []ConfigOption{WithIPAnonymizer(TruncateIP(24, 48)), WithRemoteAddr(), WithForwardedFor()},
+			wantFields: []slog.Attr{
+				slog.String("remote-addr", "192.0.2.0:1234"),
+				slog.String("forwarded-for", "203.0.113.0, 198.51.100.0, 10.0.0.0"),
+			},
+		},
+		{
+			name: "with hashed IP",
+			opts: []ConfigOption{WithIPAnonymizer(HashIP([]byte("secret"))), WithRemoteAddr()},
+			wantFields: []slog.Attr{
+				slog.String("remote-addr", "84edc40821674d125954d6546b5fe4758305af0d3533f8840740d68863b727d2:1234"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				tt.wantFields,
+				append([]string{"ttfb"}, skipFields...),
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), append([]ConfigOption{WithoutDefaultFields()}, tt.opts...)...))
+
+			// Define routes
+			router.GET("/test/:id/:name", optionalFieldsHandler)
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "http://example.com/test/1/test", nil)
+			req.Header.Set("User-Agent", "test")
+			req.Header.Set("Referer", "https://example.org/")
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Add("X-Forwarded-For", "203.0.113.1, 198.51.100.1")
+			req.Header.Add("X-Forwarded-For", "10.0.0.1")
+			req.Header.Add("Accept", "application/json")
+			req.Header.Add("Accept", "text/plain")
+			req.Header.Set("X-Api-Version", "2")
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("Cookie", "session=secret; consent=yes")
+			req.Header.Set("X-Api-Key", "secret")
+			req.TLS = &tls.ConnectionState{
+				Version:     tls.VersionTLS13,
+				CipherSuite: tls.TLS_AES_128_GCM_SHA256,
+				ServerName:  "example.com",
+			}
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, 1, handler.Records())
+		})
+	}
+}
+
+func TestNewRequestBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		contentType string
+		body        string
+		wantFields  []slog.Attr
+	}{
+		{
+			name:        "with request body",
+			opts:        []ConfigOption{WithRequestBody(1024, []string{})},
+			contentType: "application/json",
+			body:        `{"name":"test"}`,
+			wantFields: []slog.Attr{
+				slog.String("request-body", `{"name":"test"}`),
+			},
+		},
+		{
+			name:        "with truncated request body",
+			opts:        []ConfigOption{WithRequestBody(8, []string{})},
+			contentType: "application/json",
+			body:        `{"name":"test"}`,
+			wantFields: []slog.Attr{
+				slog.String("request-body", `{"name":...(truncated)`),
+			},
+		},
+		{
+			name:        "with allowed content type",
+			opts:        []ConfigOption{WithRequestBody(1024, []string{"application/json", "text/*"})},
+			contentType: "text/plain; charset=utf-8",
+			body:        "test",
+			wantFields: []slog.Attr{
+				slog.String("request-body", "test"),
+			},
+		},
+		{
+			name:        "with not allowed content type",
+			opts:        []ConfigOption{WithRequestBody(1024, []string{"application/json"})},
+			contentType: "text/plain",
+			body:        "test",
+			wantFields:  []slog.Attr{},
+		},
+		{
+			name:        "with multipart request body",
+			opts:        []ConfigOption{WithRequestBody(1024, []string{})},
+			contentType: "multipart/form-data; boundary=test",
+			body:        "--test--",
+			wantFields: []slog.Attr{
+				slog.Group("request-body",
+					slog.String("content-type", "multipart/form-data"),
+					slog.Int64("size", 8),
+				),
+			},
+		},
+		{
+			name:        "with request digest",
+			opts:        []ConfigOption{WithRequestDigest(1024)},
+			contentType: "text/plain",
+			body:        "test",
+			wantFields: []slog.Attr{
+				slog.Group("request-digest",
+					slog.String("sha256", "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"),
+					slog.Int64("size", 4),
+				),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), append([]ConfigOption{WithoutDefaultFields()}, tt.opts...)...))
+
+			// Define routes
+			router.POST("/test", func(c *gin.Context) {
+				// Check the body is still readable
+				body, err := io.ReadAll(c.Request.Body)
+				require.NoError(t, err)
+				require.Equal(t, tt.body, string(body))
+				c.JSON(200, nil)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/test", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, 1, handler.Records())
+		})
+	}
+}
+
+func TestNewRequestDigest(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxSize    int
+		body       io.Reader
+		wantFields []slog.Attr
+	}{
+		{
+			name:    "body smaller than the limit",
+			maxSize: 1024,
+			body:    strings.NewReader("test"),
+			wantFields: []slog.Attr{
+				slog.Group("request-digest",
+					slog.String("sha256", "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"),
+					slog.Int64("size", 4),
+				),
+			},
+		},
+		{
+			name:    "body larger than the limit",
+			maxSize: 2,
+			body:    strings.NewReader("test"),
+			wantFields: []slog.Attr{
+				slog.Group("request-digest",
+					slog.String("sha256", "2d6c9a90dd38f6852515274cde41a8cd8e7e1a7a053835334ec7e29f61b918dd"),
+					slog.Int64("size", 2),
+					slog.Bool("partial", true),
+				),
+			},
+		},
+		{
+			name:    "body with a read error after the limit",
+			maxSize: 4,
+			body:    io.MultiReader(strings.NewReader("test"), iotest.ErrReader(errors.New("read error"))),
+			wantFields: []slog.Attr{
+				slog.Group("request-digest",
+					slog.String("sha256", "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"),
+					slog.Int64("size", 4),
+					slog.Bool("partial", true),
+				),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), WithoutDefaultFields(), WithRequestDigest(tt.maxSize)))
+
+			// Define routes not reading the body
+			router.POST("/test", func(c *gin.Context) {
+				c.JSON(200, nil)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/test", tt.body)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, 1, handler.Records())
+		})
+	}
+}
+
+func TestNewResponseBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		contentType string
+		body        string
+		wantFields  []slog.Attr
+	}{
+		{
+			name:        "with response body",
+			opts:        []ConfigOption{WithResponseBody(1024, []string{})},
+			contentType: "application/json",
+			body:        `{"name":"test"}`,
+			wantFields: []slog.Attr{
+				slog.String("response-body", `{"name":"test"}`),
+			},
+		},
+		{
+			name:        "with truncated response body",
+			opts:        []ConfigOption{WithResponseBody(8, []string{})},
+			contentType: "application/json",
+			body:        `{"name":"test"}`,
+			wantFields: []slog.Attr{
+				slog.String("response-body", `{"name":...(truncated)`),
+			},
+		},
+		{
+			name:        "with allowed content type",
+			opts:        []ConfigOption{WithResponseBody(1024, []string{"application/json", "text/*"})},
+			contentType: "text/html; charset=utf-8",
+			body:        "<p>test</p>",
+			wantFields: []slog.Attr{
+				slog.String("response-body", "<p>test</p>"),
+			},
+		},
+		{
+			name:        "with not allowed content type",
+			opts:        []ConfigOption{WithResponseBody(1024, []string{"application/json"})},
+			contentType: "text/plain",
+			body:        "test",
+			wantFields:  []slog.Attr{},
+		},
+		{
+			name:        "with binary response body",
+			opts:        []ConfigOption{WithResponseBody(1024, []string{})},
+			contentType: "image/png",
+			body:        "\x89PNG",
+			wantFields: []slog.Attr{
+				slog.Group("response-body",
+					slog.String("content-type", "image/png"),
+					slog.Int64("size", 4),
+				),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), append([]ConfigOption{WithoutDefaultFields()}, tt.opts...)...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.Data(200, tt.contentType, []byte(tt.body))
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the response body is not altered
+			require.Equal(t, tt.body, resp.Body.String())
+
+			// Check the number of log lines
+			require.Equal(t, 1, handler.Records())
+		})
+	}
+}
+
+func TestNewBodyStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       int
+		wantFields []slog.Attr
+		wantLevel  slog.Level
+	}{
+		{
+			name:       "success",
+			code:       200,
+			wantFields: []slog.Attr{},
+			wantLevel:  slog.LevelInfo,
+		},
+		{
+			name: "server error",
+			code: 500,
+			wantFields: []slog.Attr{
+				slog.String("request-body", "request"),
+				slog.String("response-body", "response"),
+			},
+			wantLevel: slog.LevelError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				tt.wantLevel,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(
+				slog.New(handler),
+				WithoutDefaultFields(),
+				WithRequestBody(1024, []string{}),
+				WithResponseBody(1024, []string{}),
+				WithBodyStatus([]string{HTTPServerErrorRegex}),
+			))
+
+			// Define routes
+			router.POST("/test", func(c *gin.Context) {
+				c.Data(tt.code, "text/plain", []byte("response"))
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/test", strings.NewReader("request"))
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, 1, handler.Records())
+		})
+	}
+}
+
+func TestNewParsedUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		wantField slog.Attr
+	}{
+		{
+			name:      "chrome desktop",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			wantField: slog.Group("user-agent",
+				slog.String("browser", "Chrome"),
+				slog.String("os", "Windows"),
+				slog.String("device", "desktop"),
+				slog.Bool("bot", false),
+			),
+		},
+		{
+			name:      "safari mobile",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+			wantField: slog.Group("user-agent",
+				slog.String("browser", "Safari"),
+				slog.String("os", "iOS"),
+				slog.String("device", "mobile"),
+				slog.Bool("bot", false),
+			),
+		},
+		{
+			name:      "firefox tablet",
+			userAgent: "Mozilla/5.0 (Android 13; Tablet; rv:120.0) Gecko/120.0 Firefox/120.0",
+			wantField: slog.Group("user-agent",
+				slog.String("browser", "Firefox"),
+				slog.String("os", "Android"),
+				slog.String("device", "tablet"),
+				slog.Bool("bot", false),
+			),
+		},
+		{
+			name:      "crawler",
+			userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			wantField: slog.Group("user-agent",
+				slog.String("browser", "other"),
+				slog.String("os", "other"),
+				slog.String("device", "bot"),
+				slog.Bool("bot", true),
+			),
+		},
+		{
+			name:      "curl",
+			userAgent: "curl/8.4.0",
+			wantField: slog.Group("user-agent",
+				slog.String("browser", "other"),
+				slog.String("os", "other"),
+				slog.String("device", "bot"),
+				slog.Bool("bot", true),
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				[]slog.Attr{tt.wantField},
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(
+				slog.New(handler),
+				WithoutIP(),
+				WithoutStatus(),
+				WithoutMethod(),
+				WithoutPath(),
+				WithoutLatency(),
+				WithoutBytesIn(),
+				WithoutBytesOut(),
+				WithoutRequestID(),
+				WithParsedUserAgent(),
+			))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.JSON(200, nil)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			req.Header.Set("User-Agent", tt.userAgent)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, 1, handler.Records())
+		})
+	}
+}
+
+func TestNewRedaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []ConfigOption
+		wantFields []slog.Attr
+	}{
+		{
+			name: "default redactor",
+			opts: []ConfigOption{},
+			wantFields: []slog.Attr{
+				slog.String("path", "/users/john@example.com"),
+				slog.String("referer", "https://example.org/?token=secret"),
+			},
+		},
+		{
+			name: "with redactor",
+			opts: []ConfigOption{
+				WithRedactor(redact.New(
+					redact.WithQueryKeys([]string{"^token$"}),
+					redact.WithValuePatterns([]string{redact.EmailPattern}),
+				)),
+			},
+			wantFields: []slog.Attr{
+				slog.String("path", "/users/[REDACTED]"),
+				slog.String("referer", "https://example.org/?token=[REDACTED]"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), append([]ConfigOption{
+				WithoutIP(),
+				WithoutStatus(),
+				WithoutMethod(),
+				WithoutUserAgent(),
+				WithoutLatency(),
+				WithoutBytesIn(),
+				WithoutBytesOut(),
+				WithoutRequestID(),
+				WithReferer(),
+			}, tt.opts...)...))
+
+			// Define routes
+			router.GET("/users/:email", func(c *gin.Context) {
+				c.JSON(200, nil)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/users/john@example.com", nil)
+			req.Header.Set("Referer", "https://example.org/?token=secret")
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, 1, handler.Records())
+		})
+	}
+}
+
+func TestNewMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		wantMessage string
+		wantPanic   bool
+	}{
+		{
+			name:        "default message",
+			opts:        []ConfigOption{},
+			wantMessage: DefaultMessage,
+		},
+		{
+			name:        "with message",
+			opts:        []ConfigOption{WithMessage("Requête entrante")},
+			wantMessage: "Requête entrante",
+		},
+		{
+			name: "with message func",
+			opts: []ConfigOption{
+				WithMessageFunc(func(c *gin.Context) string {
+					return fmt.Sprintf("%s %s %d", c.Request.Method, c.Request.URL.Path, c.Writer.Status())
+				}),
+			},
+			wantMessage: "GET /test 201",
+		},
+		{
+			name:      "with nil message func",
+			opts:      []ConfigOption{WithMessageFunc(nil)},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger capturing the message
+			var message string
+			logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.MessageKey {
+						message = a.Value.String()
+					}
+					return a
+				},
+			}))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			if tt.wantPanic {
+				require.Panics(t, func() { router.Use(New(logger, tt.opts...)) })
+				return
+			}
+			router.Use(New(logger, tt.opts...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.JSON(201, nil)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the message
+			require.Equal(t, tt.wantMessage, message)
+		})
+	}
+}
+
+func TestNewErrorLogger(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		level          slog.Level
+		wantRecords    int
+		wantErrRecords int
+	}{
+		{
+			name:           "success",
+			status:         http.StatusOK,
+			level:          slog.LevelInfo,
+			wantRecords:    1,
+			wantErrRecords: 0,
+		},
+		{
+			name:           "client error",
+			status:         http.StatusNotFound,
+			level:          slog.LevelWarn,
+			wantRecords:    1,
+			wantErrRecords: 0,
+		},
+		{
+			name:           "server error",
+			status:         http.StatusInternalServerError,
+			level:          slog.LevelError,
+			wantRecords:    0,
+			wantErrRecords: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create the loggers with mock handlers
+			fields := []slog.Attr{slog.Int("status", tt.status)}
+			handler := slogtest.NewMockHandler(slog.NewTextHandler(os.Stderr, nil), t, tt.level, fields, skipFields)
+			errHandler := slogtest.NewMockHandler(slog.NewTextHandler(os.Stderr, nil), t, tt.level, fields, skipFields)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(
+				slog.New(handler),
+				WithoutIP(),
+				WithoutMethod(),
+				WithoutPath(),
+				WithoutUserAgent(),
+				WithoutLatency(),
+				WithoutBytesIn(),
+				WithoutBytesOut(),
+				WithoutRequestID(),
+				WithErrorLogger(slog.New(errHandler)),
+			))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(tt.status)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines of each logger
+			require.Equal(t, tt.wantRecords, handler.Records())
+			require.Equal(t, tt.wantErrRecords, errHandler.Records())
+		})
+	}
+}
+
+// contextHandler is a slog handler capturing the context value of contextKey.
+type contextHandler struct {
+	slog.Handler
+	value any
+}
+
+// contextKey is the context key captured by contextHandler.
+type contextKey struct{}
+
+// Handle implements Handler.Handle.
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.value = ctx.Value(contextKey{})
+	return h.Handler.Handle(ctx, r)
+}
+
+func TestNewRequestContext(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ConfigOption
+		wantValue any
+	}{
+		{
+			name:      "with request context",
+			opts:      []ConfigOption{},
+			wantValue: "value",
+		},
+		{
+			name:      "without request context",
+			opts:      []ConfigOption{WithoutRequestContext()},
+			wantValue: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger capturing the context
+			handler := &contextHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), tt.opts...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			ctx := context.WithValue(context.Background(), contextKey{}, "value")
+			req, err := http.NewRequestWithContext(ctx, "GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the context passed to the handler
+			require.Equal(t, tt.wantValue, handler.value)
+		})
+	}
+}
+
+func TestNewContextAttrs(t *testing.T) {
+	type tenantKey struct{}
+
+	// Create a new logger with a mock handler
+	handler := slogtest.NewMockHandler(
+		slog.NewTextHandler(os.Stderr, nil),
+		t,
+		slog.LevelInfo,
+		[]slog.Attr{
+			slog.Int("status", 200),
+			slog.Any("tenant", "acme"),
+			slog.Any("user", 42),
+		},
+		skipFields,
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), tenantKey{}, "acme")
+		ctx = context.WithValue(ctx, "user-id", 42) //nolint: staticcheck
+		c.Request = c.Request.WithContext(ctx)
+	})
+	router.Use(New(
+		slog.New(handler),
+		WithoutIP(),
+		WithoutMethod(),
+		WithoutPath(),
+		WithoutUserAgent(),
+		WithoutLatency(),
+		WithoutBytesIn(),
+		WithoutBytesOut(),
+		WithoutRequestID(),
+		WithContextAttrs(map[any]string{
+			"user-id":   "user",
+			tenantKey{}: "tenant",
+			"trace-id":  "trace",
+		}),
+	))
+
+	// Define routes
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// Create a new request
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/test", nil)
+	require.NoError(t, err)
+	router.ServeHTTP(resp, req)
+
+	// Check the number of log lines
+	require.Equal(t, 1, handler.Records())
+}
+
+func TestNewOTelLatency(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ConfigOption
+	}{
+		{
+			name: "OpenTelemetry semantic conventions",
+			opts: []ConfigOption{WithOTelSemConv()},
+		},
+		{
+			name: "with pretty latency",
+			opts: []ConfigOption{WithOTelSemConv(), WithPrettyLatency()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger writing JSON lines
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(logger, tt.opts...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				time.Sleep(2 * time.Millisecond)
+				c.Status(http.StatusOK)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the duration is a number of seconds
+			record := map[string]any{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			duration, ok := record["http.server.request.duration"].(float64)
+			require.True(t, ok)
+			require.Greater(t, duration, 0.001)
+			require.Less(t, duration, 1.0)
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ConfigOption
+	}{
+		{
+			name: "excluded path",
+			opts: []ConfigOption{WithBlacklistPathExact([]string{"/excluded"})},
+		},
+		{
+			name: "excluded path with malformed requests",
+			opts: []ConfigOption{WithBlacklistPathExact([]string{"/excluded"}), WithMalformedRequests()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Set a fixed random seed to get a fixed request ID
+			uuid.SetRand(rand.New(rand.NewSource(1)))
+
+			// Create a new logger writing JSON lines
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(logger, tt.opts...))
+
+			// Define routes
+			router.GET("/test/:id", func(c *gin.Context) {
+				FromContext(c).Info("Handler")
+				c.Status(http.StatusOK)
+			})
+			router.GET("/excluded", func(c *gin.Context) {
+				require.Equal(t, logger, FromContext(c))
+				FromContext(c).Info("Excluded")
+				c.Status(http.StatusOK)
+			})
+
+			// Create the requests
+			for _, path := range []string{"/test/1", "/excluded"} {
+				resp := httptest.NewRecorder()
+				req, err := http.NewRequest("GET", path, nil)
+				require.NoError(t, err)
+				req.RemoteAddr = "192.0.2.1:1234"
+				router.ServeHTTP(resp, req)
+			}
+
+			// Check the handler log lines
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, 3)
+			record := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+			require.Equal(t, "Handler", record["msg"])
+			require.Equal(t, "192.0.2.1", record["ip"])
+			require.Equal(t, "52fdfc07-2182-454f-963f-5f0f9a621d72", record["request-id"])
+			require.Equal(t, "/test/:id", record["route"])
+
+			// Check the excluded handler log line has no request attributes
+			record = map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(lines[2]), &record))
+			require.Equal(t, "Excluded", record["msg"])
+			require.NotContains(t, record, "request-id")
+			require.NotContains(t, record, "route")
+		})
+	}
+}
+
+func TestNewSkipStatus(t *testing.T) {
+	// Create a new logger with a mock handler
+	handler := slogtest.NewMockHandler(
+		slog.NewTextHandler(os.Stderr, nil),
+		t,
+		slog.LevelError,
+		[]slog.Attr{
+			slog.Int("status", 500),
+			slog.String("path", "/test/500"),
+		},
+		skipFields,
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(New(
+		slog.New(handler),
+		WithoutIP(),
+		WithoutMethod(),
+		WithoutUserAgent(),
+		WithoutLatency(),
+		WithoutBytesIn(),
+		WithoutBytesOut(),
+		WithoutRequestID(),
+		WithSkipStatus([]int{200, 304}),
+	))
+
+	// Define routes
+	router.GET("/test/:status", func(c *gin.Context) {
+		code, err := strconv.Atoi(c.Param("status"))
+		require.NoError(t, err)
+		c.Status(code)
+	})
+
+	// Create the requests
+	for _, status := range []string{"200", "304", "500"} {
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/test/"+status, nil)
+		require.NoError(t, err)
+		router.ServeHTTP(resp, req)
+	}
+
+	// Check the number of log lines
+	require.Equal(t, 1, handler.Records())
+}
+
+func TestNewSkipHealthChecks(t *testing.T) {
+	// Create a new logger with a mock handler
+	handler := slogtest.NewMockHandler(
+		slog.NewTextHandler(os.Stderr, nil),
+		t,
+		slog.LevelInfo,
+		[]slog.Attr{
+			slog.String("path", "/test"),
+		},
+		skipFields,
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(New(
+		slog.New(handler),
+		WithoutIP(),
+		WithoutStatus(),
+		WithoutMethod(),
+		WithoutUserAgent(),
+		WithoutLatency(),
+		WithoutBytesIn(),
+		WithoutBytesOut(),
+		WithoutRequestID(),
+		WithSkipHealthChecks(),
+		WithPathNormalization(true),
+	))
+
+	// Define routes
+	var requestID string
+	router.GET("/*path", func(c *gin.Context) {
+		requestID = GetRequestID(c)
+		c.Status(http.StatusOK)
+	})
+
+	// Create the requests
+	requests := []struct {
+		path      string
+		userAgent string
+	}{
+		{path: "/healthz", userAgent: "test"},
+		{path: "/HEALTHZ/", userAgent: "test"},
+		{path: "/livez", userAgent: "test"},
+		{path: "/readyz", userAgent: "test"},
+		{path: "/ping", userAgent: "test"},
+		{path: "/metrics", userAgent: "test"},
+		{path: "/test", userAgent: "kube-probe/1.29"},
+		{path: "/test", userAgent: "test"},
+	}
+	for _, r := range requests {
+		requestID = ""
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", r.path, nil)
+		require.NoError(t, err)
+		req.Header.Set("User-Agent", r.userAgent)
+		router.ServeHTTP(resp, req)
+
+		// Check the request ID is only set for the logged requests
+		require.Equal(t, r.path == "/test" && r.userAgent == "test", requestID != "")
+	}
+
+	// Check the number of log lines
+	require.Equal(t, 1, handler.Records())
+}
+
+func TestNewLevels(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ConfigOption
+		path      string
+		code      int
+		wantLevel slog.Level
+	}{
+		{
+			name:      "path level",
+			opts:      []ConfigOption{WithPathLevels(map[string]slog.Level{"^/poll$": slog.LevelDebug})},
+			path:      "/poll",
+			code:      500,
+			wantLevel: slog.LevelDebug,
+		},
+		{
+			name:      "path level not matched",
+			opts:      []ConfigOption{WithPathLevels(map[string]slog.Level{"^/poll$": slog.LevelDebug})},
+			path:      "/test",
+			code:      500,
+			wantLevel: slog.LevelError,
+		},
+		{
+			name: "path levels lexical order",
+			opts: []ConfigOption{
+				WithPathLevels(map[string]slog.Level{"^/poll": slog.LevelDebug, "^/p": slog.LevelWarn}),
+			},
+			path:      "/poll",
+			code:      200,
+			wantLevel: slog.LevelWarn,
+		},
+		{
+			name:      "method level",
+			opts:      []ConfigOption{WithMethodLevels(map[string]slog.Level{"get": slog.LevelDebug})},
+			path:      "/test",
+			code:      200,
+			wantLevel: slog.LevelDebug,
+		},
+		{
+			name:      "method level with error",
+			opts:      []ConfigOption{WithMethodLevels(map[string]slog.Level{"GET": slog.LevelDebug})},
+			path:      "/test",
+			code:      404,
+			wantLevel: slog.LevelWarn,
+		},
+		{
+			name:      "method level not matched",
+			opts:      []ConfigOption{WithMethodLevels(map[string]slog.Level{"POST": slog.LevelWarn})},
+			path:      "/test",
+			code:      200,
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "level func",
+			opts: []ConfigOption{
+				WithPathLevels(map[string]slog.Level{"^/test$": slog.LevelDebug}),
+				WithLevelFunc(func(c *gin.Context) slog.Level {
+					if c.Writer.Status() == 404 && c.Request.URL.Path == "/test" {
+						return slog.LevelError
+					}
+					return slog.LevelInfo
+				}),
+			},
+			path:      "/test",
+			code:      404,
+			wantLevel: slog.LevelError,
+		},
+		{
+			name: "slow request threshold",
+			opts: []ConfigOption{
+				WithSlowRequestThresholds(map[time.Duration]slog.Level{time.Nanosecond: slog.LevelWarn, time.Hour: slog.LevelError}),
+			},
+			path:      "/test",
+			code:      200,
+			wantLevel: slog.LevelWarn,
+		},
+		{
+			name: "slow request threshold does not lower the level",
+			opts: []ConfigOption{
+				WithSlowRequestThresholds(map[time.Duration]slog.Level{time.Nanosecond: slog.LevelWarn}),
+			},
+			path:      "/test",
+			code:      500,
+			wantLevel: slog.LevelError,
+		},
+		{
+			name: "slow request threshold not exceeded",
+			opts: []ConfigOption{
+				WithSlowRequestThresholds(map[time.Duration]slog.Level{time.Hour: slog.LevelError}),
+			},
+			path:      "/test",
+			code:      200,
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "status range levels",
+			opts: []ConfigOption{
+				WithStatusRangeLevels([]StatusRange{
+					{Min: 200, Max: 399, Level: slog.LevelDebug},
+					{Min: 404, Max: 404, Level: slog.LevelInfo},
+					{Min: 400, Max: 599, Level: slog.LevelError},
+				}),
+			},
+			path:      "/test",
+			code:      404,
+			wantLevel: slog.LevelInfo,
+		},
+		{
+			name: "status range levels default level",
+			opts: []ConfigOption{
+				WithDefaultLevel(slog.LevelWarn),
+				WithStatusRangeLevels([]StatusRange{{Min: 200, Max: 299, Level: slog.LevelDebug}}),
+			},
+			path:      "/test",
+			code:      500,
+			wantLevel: slog.LevelWarn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}),
+				t,
+				tt.wantLevel,
+				[]slog.Attr{slog.String("path", tt.path)},
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), append([]ConfigOption{
+				WithoutIP(),
+				WithoutStatus(),
+				WithoutMethod(),
+				WithoutUserAgent(),
+				WithoutLatency(),
+				WithoutBytesIn(),
+				WithoutBytesOut(),
+				WithoutRequestID(),
+			}, tt.opts...)...))
+
+			// Define routes
+			router.GET("/*path", func(c *gin.Context) {
+				c.Status(tt.code)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tt.path, nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, 1, handler.Records())
+		})
+	}
+}
+
+func TestNewLevelers(t *testing.T) {
+	tests := []struct {
+		name string
+		opts func(level *slog.LevelVar) []ConfigOption
+	}{
+		{
+			name: "default level",
+			opts: func(level *slog.LevelVar) []ConfigOption {
+				return []ConfigOption{WithDefaultLevel(level), WithHTTPLevels(nil)}
+			},
+		},
+		{
+			name: "http levelers",
+			opts: func(level *slog.LevelVar) []ConfigOption {
+				return []ConfigOption{WithHTTPLevelers(map[string]slog.Leveler{"2..": level})}
+			},
+		},
+		{
+			name: "status range levels",
+			opts: func(level *slog.LevelVar) []ConfigOption {
+				return []ConfigOption{WithStatusRangeLevels([]StatusRange{{Min: 200, Max: 299, Level: level}})}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger capturing the level
+			var level slog.Value
+			logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{
+				Level: slog.LevelDebug,
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.LevelKey {
+						level = a.Value
+					}
+					return a
+				},
+			}))
+
+			levelVar := &slog.LevelVar{}
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(logger, tt.opts(levelVar)...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			// Change the level at runtime
+			for _, want := range []slog.Level{slog.LevelInfo, slog.LevelDebug, slog.LevelWarn} {
+				levelVar.Set(want)
+
+				// Create a new request
+				resp := httptest.NewRecorder()
+				req, err := http.NewRequest("GET", "/test", nil)
+				require.NoError(t, err)
+				router.ServeHTTP(resp, req)
+
+				// Check the level
+				require.Equal(t, want, level.Any())
+			}
+		})
+	}
+}
+
+func TestNewPathNormalization(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		path        string
+		wantFields  []slog.Attr
+		wantRecords int
+	}{
+		{
+			name:        "without normalization",
+			opts:        []ConfigOption{WithBlacklistPathExact([]string{"/test/users"})},
+			path:        "//test//users/",
+			wantFields:  []slog.Attr{slog.String("path", "//test//users/")},
+			wantRecords: 1,
+		},
+		{
+			name:        "with normalization",
+			opts:        []ConfigOption{WithPathNormalization(false)},
+			path:        "//test//Users/",
+			wantFields:  []slog.Attr{slog.String("path", "/test/Users")},
+			wantRecords: 1,
+		},
+		{
+			name:        "with lowercase normalization",
+			opts:        []ConfigOption{WithPathNormalization(true)},
+			path:        "/Test/Users/",
+			wantFields:  []slog.Attr{slog.String("path", "/test/users")},
+			wantRecords: 1,
+		},
+		{
+			name:        "with normalization root path",
+			opts:        []ConfigOption{WithPathNormalization(true)},
+			path:        "//",
+			wantFields:  []slog.Attr{slog.String("path", "/")},
+			wantRecords: 1,
+		},
+		{
+			name: "with normalization blacklist",
+			opts: []ConfigOption{
+				WithPathNormalization(true),
+				WithBlacklistPathExact([]string{"/test/users"}),
+			},
+			path:        "//test//Users/",
+			wantFields:  []slog.Attr{},
+			wantRecords: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.RemoveExtraSlash = false
+			router.RedirectTrailingSlash = false
+			router.Use(New(slog.New(handler), append([]ConfigOption{
+				WithoutIP(),
+				WithoutStatus(),
+				WithoutMethod(),
+				WithoutUserAgent(),
+				WithoutLatency(),
+				WithoutBytesIn(),
+				WithoutBytesOut(),
+				WithoutRequestID(),
+			}, tt.opts...)...))
+
+			// Define routes
+			router.NoRoute(func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "http://example.com"+tt.path, nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, tt.wantRecords, handler.Records())
+		})
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	base := NewConfig(
+		WithoutIP(),
+		WithoutMethod(),
+		WithoutUserAgent(),
+		WithoutLatency(),
+		WithoutBytesIn(),
+		WithoutBytesOut(),
+		WithoutRequestID(),
+		WithStaticAttrs([]slog.Attr{slog.String("service", "api")}),
+	)
+
+	tests := []struct {
+		name       string
+		config     *Config
+		path       string
+		wantFields []slog.Attr
+	}{
+		{
+			name:   "base config",
+			config: base,
+			path:   "/public",
+			wantFields: []slog.Attr{
+				slog.Int("status", 200),
+				slog.String("path", "/public"),
+				slog.String("service", "api"),
+			},
+		},
+		{
+			name:   "cloned config",
+			config: base.Clone(WithoutStatus(), WithHost(), WithStaticAttrs([]slog.Attr{slog.String("group", "admin")})),
+			path:   "/admin",
+			wantFields: []slog.Attr{
+				slog.String("path", "/admin"),
+				slog.String("host", "example.com"),
+				slog.String("service", "api"),
+				slog.String("group", "admin"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(NewFromConfig(slog.New(handler), tt.config))
+
+			// Define routes
+			router.GET(tt.path, func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "http://example.com"+tt.path, nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, 1, handler.Records())
+		})
+	}
+}
+
+func TestNewSampling(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		wantRecords int
+	}{
+		{
+			name:        "without sampling",
+			opts:        []ConfigOption{},
+			wantRecords: 4,
+		},
+		{
+			name:        "with sampling none",
+			opts:        []ConfigOption{WithSampling(0)},
+			wantRecords: 2,
+		},
+		{
+			name:        "with sampling all",
+			opts:        []ConfigOption{WithSampling(1)},
+			wantRecords: 4,
+		},
+		{
+			name:        "with class sampling",
+			opts:        []ConfigOption{WithClassSampling(map[int]float64{2: 0, 4: 0})},
+			wantRecords: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				[]slog.Attr{slog.String("service", "api")},
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), append([]ConfigOption{
+				WithoutDefaultFields(),
+				WithStaticAttrs([]slog.Attr{slog.String("service", "api")}),
+				WithLevelFunc(func(c *gin.Context) slog.Level { return slog.LevelInfo }),
+			}, tt.opts...)...))
+
+			// Define routes
+			router.GET("/test/:status", func(c *gin.Context) {
+				code, err := strconv.Atoi(c.Param("status"))
+				require.NoError(t, err)
+				c.Status(code)
+			})
+
+			// Create the requests
+			for _, status := range []string{"200", "304", "404", "500"} {
+				resp := httptest.NewRecorder()
+				req, err := http.NewRequest("GET", "/test/"+status, nil)
+				require.NoError(t, err)
+				router.ServeHTTP(resp, req)
+			}
+
+			// Check the number of log lines
+			require.Equal(t, tt.wantRecords, handler.Records())
+		})
+	}
+}
+
+func TestNewAdaptiveSampling(t *testing.T) {
+	// Create a new logger with a mock handler
+	handler := slogtest.NewMockHandler(
+		slog.NewTextHandler(os.Stderr, nil),
+		t,
+		slog.LevelInfo,
+		[]slog.Attr{slog.Float64("sample-rate", 1), slog.String("service", "api")},
+		skipFields,
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(New(
+		slog.New(handler),
+		WithoutDefaultFields(),
+		WithStaticAttrs([]slog.Attr{slog.String("service", "api")}),
+		WithLevelFunc(func(c *gin.Context) slog.Level { return slog.LevelInfo }),
+		WithAdaptiveSampling(1),
+	))
+
+	// Define routes
+	router.GET("/test/:status", func(c *gin.Context) {
+		code, err := strconv.Atoi(c.Param("status"))
+		require.NoError(t, err)
+		c.Status(code)
+	})
+
+	// Create the requests
+	for _, status := range []string{"200", "200", "200", "500"} {
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/test/"+status, nil)
+		require.NoError(t, err)
+		router.ServeHTTP(resp, req)
+	}
+
+	// Check the number of log lines
+	require.Equal(t, 2, handler.Records())
+}
+
+func TestAdaptiveSampler(t *testing.T) {
+	sampler := newAdaptiveSampler(10)
+	start := time.Now()
+
+	// First window: the maximum number of lines is logged
+	logged := 0
+	for i := 0; i < 40; i++ {
+		if sampled, rate := sampler.sample(start); sampled {
+			require.Equal(t, 1.0, rate)
+			logged++
+		}
+	}
+	require.Equal(t, 10, logged)
+
+	// Second window: the rate is computed from the first window
+	_, rate := sampler.sample(start.Add(time.Second))
+	require.Equal(t, 0.25, rate)
+
+	// Window after an idle period: the rate is reset
+	_, rate = sampler.sample(start.Add(5 * time.Second))
+	require.Equal(t, 1.0, rate)
+}
+
+func TestNewClientRateLimit(t *testing.T) {
+	// Create a new logger with a mock handler
+	handler := slogtest.NewMockHandler(
+		slog.NewTextHandler(os.Stderr, nil),
+		t,
+		slog.LevelInfo,
+		[]slog.Attr{slog.String("remote-addr", "")},
+		[]string{"remote-addr"},
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(New(slog.New(handler), WithoutDefaultFields(), WithRemoteAddr(), WithClientRateLimit(1, time.Hour)))
+
+	// Define routes
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// Create the requests
+	for _, remoteAddr := range []string{"192.0.2.1:1234", "192.0.2.1:1235", "192.0.2.2:1234", "192.0.2.1:1236"} {
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/test", nil)
+		require.NoError(t, err)
+		req.RemoteAddr = remoteAddr
+		router.ServeHTTP(resp, req)
+	}
+
+	// Check the number of log lines
+	require.Equal(t, 2, handler.Records())
+}
+
+func TestClientLimiter(t *testing.T) {
+	limiter := newClientLimiter(2, time.Minute)
+	start := time.Now()
+
+	// First interval
+	for i, want := range []bool{true, true, false, false} {
+		allowed, suppressed := limiter.allow("192.0.2.1", start.Add(time.Duration(i)*time.Second))
+		require.Equal(t, want, allowed)
+		require.Nil(t, suppressed)
+	}
+	allowed, _ := limiter.allow("192.0.2.2", start)
+	require.True(t, allowed)
+
+	// Second interval: the suppressed lines are returned
+	allowed, suppressed := limiter.allow("192.0.2.2", start.Add(time.Minute))
+	require.True(t, allowed)
+	require.Equal(t, map[string]int{"192.0.2.1": 2}, suppressed)
+}
+
+func TestNewDeduplication(t *testing.T) {
+	// Create a new logger with a mock handler
+	handler := slogtest.NewMockHandler(
+		slog.NewTextHandler(os.Stderr, nil),
+		t,
+		slog.LevelInfo,
+		[]slog.Attr{slog.String("path", "")},
+		[]string{"path"},
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(New(
+		slog.New(handler),
+		WithoutIP(),
+		WithoutStatus(),
+		WithoutMethod(),
+		WithoutUserAgent(),
+		WithoutLatency(),
+		WithoutBytesIn(),
+		WithoutBytesOut(),
+		WithoutRequestID(),
+		WithDeduplication(time.Hour),
+	))
+
+	// Define routes
+	router.GET("/*path", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// Create the requests
+	for _, path := range []string{"/test1", "/test1", "/test2", "/test1"} {
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", path, nil)
+		require.NoError(t, err)
+		router.ServeHTTP(resp, req)
+	}
+
+	// Check the number of log lines
+	require.Equal(t, 2, handler.Records())
+}
+
+func TestDeduplicator(t *testing.T) {
+	deduplicator := newDeduplicator(time.Minute)
+	start := time.Now()
+	key := dedupKey{path: "/test", status: 500, level: slog.LevelError}
+
+	// First window
+	for i, want := range []bool{true, false, false} {
+		first, expired := deduplicator.check(key, start.Add(time.Duration(i)*time.Second))
+		require.Equal(t, want, first)
+		require.Nil(t, expired)
+	}
+
+	// Second window: the duplicates count is returned
+	first, expired := deduplicator.check(key, start.Add(time.Minute))
+	require.True(t, first)
+	require.Equal(t, map[dedupKey]int{key: 2}, expired)
+}
+
+func TestNewSummary(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		wantFields  []slog.Attr
+		wantRecords int
+	}{
+		{
+			name:        "with summary only",
+			opts:        []ConfigOption{WithSummary(time.Hour, false)},
+			wantFields:  []slog.Attr{},
+			wantRecords: 0,
+		},
+		{
+			name: "with summary every request",
+			opts: []ConfigOption{WithSummary(0, false)},
+			wantFields: []slog.Attr{
+				slog.String("route", "/test/:status"),
+				slog.Int("requests", 1),
+				slog.Int("client-errors", 1),
+				slog.Int("server-errors", 0),
+				slog.Group("latency"),
+			},
+			wantRecords: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), tt.opts...))
+
+			// Define routes
+			router.GET("/test/:status", func(c *gin.Context) {
+				c.Status(http.StatusNotFound)
+			})
+
+			// Create the requests
+			for i := 0; i < 3; i++ {
+				resp := httptest.NewRecorder()
+				req, err := http.NewRequest("GET", "/test/404", nil)
+				require.NoError(t, err)
+				router.ServeHTTP(resp, req)
+			}
+
+			// Check the number of log lines
+			require.Equal(t, tt.wantRecords, handler.Records())
+		})
+	}
+}
+
+func TestNewSummaryUnmatched(t *testing.T) {
+	// Create a new logger writing JSON lines
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	ctrl := NewController(logger, WithSummary(time.Hour, false))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ctrl.Handler())
+
+	// Create the requests to unknown paths
+	for _, path := range []string{"/unknown/1", "/unknown/2", "/.env"} {
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", path, nil)
+		require.NoError(t, err)
+		router.ServeHTTP(resp, req)
+	}
+
+	// Flush the summary
+	require.NoError(t, ctrl.Update())
+
+	// Check the unknown paths are aggregated
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+	record := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	require.Equal(t, unmatchedRoute, record["route"])
+	require.Equal(t, float64(3), record["requests"])
+	require.Equal(t, float64(3), record["client-errors"])
+}
+
+func TestSummarizer(t *testing.T) {
+	summarizer := newSummarizer(time.Minute, true)
+	start := time.Now()
+
+	// First interval
+	for i := 1; i <= 100; i++ {
+		status := 200
+		switch {
+		case i%10 == 0:
+			status = 500
+		case i%5 == 0:
+			status = 404
+		}
+		require.Nil(t, summarizer.record("/test", status, time.Duration(i)*time.Millisecond, start))
+	}
+	require.Nil(t, summarizer.record("/other", 200, time.Millisecond, start))
+
+	// Second interval: the statistics of the first interval are returned
+	previous := summarizer.record("/test", 200, time.Millisecond, start.Add(time.Minute))
+	require.Len(t, previous, 2)
+	require.Equal(t, "/other", previous[0].route)
+	require.Equal(t, []slog.Attr{
+		slog.String("route", "/test"),
+		slog.Int("requests", 100),
+		slog.Int("client-errors", 10),
+		slog.Int("server-errors", 10),
+		slog.Group("latency",
+			slog.Duration("p50", 50*time.Millisecond),
+			slog.Duration("p90", 90*time.Millisecond),
+			slog.Duration("p99", 99*time.Millisecond),
+		),
+	}, previous[1].attrs())
+}
+
+func TestNewRequestStartLog(t *testing.T) {
+	// Set a fixed random seed to get a fixed request ID
+	uuid.SetRand(rand.New(rand.NewSource(1)))
+
+	// Create a new logger with a mock handler
+	handler := slogtest.NewMockHandler(
+		slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		t,
+		slog.LevelDebug,
+		[]slog.Attr{
+			slog.String("method", "GET"),
+			slog.String("path", "/test"),
+			slog.String("request-id", "52fdfc07-2182-454f-963f-5f0f9a621d72"),
+		},
+		skipFields,
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(New(
+		slog.New(handler),
+		WithoutIP(),
+		WithoutStatus(),
+		WithoutUserAgent(),
+		WithoutLatency(),
+		WithoutBytesIn(),
+		WithoutBytesOut(),
+		WithDefaultLevel(slog.LevelDebug),
+		WithStatusRangeLevels(nil),
+		WithRequestStartLog(slog.LevelDebug),
+	))
+
+	// Define routes
+	router.GET("/test", func(c *gin.Context) {
+		// Check the request start is logged before the handlers
+		require.Equal(t, 1, handler.Records())
+		c.Status(http.StatusOK)
+	})
+
+	// Create a new request
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/test", nil)
+	require.NoError(t, err)
+	router.ServeHTTP(resp, req)
+
+	// Check the number of log lines
+	require.Equal(t, 2, handler.Records())
+}
+
+// hijackRecorder is a httptest.ResponseRecorder supporting the hijacking.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+// Hijack implements http.Hijacker.Hijack.
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return r.conn, bufio.NewReadWriter(bufio.NewReader(r.conn), bufio.NewWriter(r.conn)), nil
+}
+
+func TestNewLongLivedConnections(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       http.Header
+		wantMessages []string
+		wantBytesIn  float64
+		wantBytesOut float64
+	}{
+		{
+			name:         "request",
+			header:       http.Header{},
+			wantMessages: []string{"Incoming request"},
+			wantBytesIn:  0,
+			wantBytesOut: 2,
+		},
+		{
+			name:         "server-sent events",
+			header:       http.Header{"Accept": []string{"text/event-stream"}},
+			wantMessages: []string{"Connection opened", "Connection closed"},
+			wantBytesIn:  0,
+			wantBytesOut: 2,
+		},
+		{
+			name:         "websocket",
+			header:       http.Header{"Connection": []string{"Upgrade"}, "Upgrade": []string{"websocket"}},
+			wantMessages: []string{"Connection opened", "Connection closed"},
+			wantBytesIn:  3,
+			wantBytesOut: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger writing JSON lines
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(logger, WithLongLivedConnections()))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				if c.GetHeader("Upgrade") == "" {
+					c.String(http.StatusOK, "ok")
+					return
+				}
+
+				// Exchange messages on the hijacked connection
+				conn, _, err := c.Writer.Hijack()
+				require.NoError(t, err)
+				defer conn.Close()
+				_, err = conn.Write([]byte("hello"))
+				require.NoError(t, err)
+				_, err = io.ReadFull(conn, make([]byte, 3))
+				require.NoError(t, err)
+			})
+
+			// Create a new client connection
+			server, client := net.Pipe()
+			defer client.Close()
+			go func() {
+				_, _ = io.ReadFull(client, make([]byte, 5)) //nolint: errcheck
+				_, _ = client.Write([]byte("bye"))          //nolint: errcheck
+			}()
+
+			// Create a new request
+			resp := &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: server}
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			req.Header = tt.header
+			router.ServeHTTP(resp, req)
+
+			// Check the log lines
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, len(tt.wantMessages))
+			var record map[string]any
+			for i, line := range lines {
+				record = map[string]any{}
+				require.NoError(t, json.Unmarshal([]byte(line), &record))
+				require.Equal(t, tt.wantMessages[i], record["msg"])
+			}
+
+			// Check the last log line fields
+			if len(tt.wantMessages) > 1 {
+				require.Contains(t, record, "duration")
+				require.NotContains(t, record, "latency")
+			}
+			require.Equal(t, tt.wantBytesIn, record["bytes-in"])
+			require.Equal(t, tt.wantBytesOut, record["bytes-out"])
+		})
+	}
+}
+
+func TestNewClientAborts(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []ConfigOption
+		canceled   bool
+		wantFields []slog.Attr
+	}{
+		{
+			name:     "without client aborts",
+			opts:     []ConfigOption{},
+			canceled: true,
+			wantFields: []slog.Attr{
+				slog.Int("status", 200),
+			},
+		},
+		{
+			name:     "with client aborts",
+			opts:     []ConfigOption{WithClientAborts(0)},
+			canceled: true,
+			wantFields: []slog.Attr{
+				slog.Int("status", 200),
+				slog.Bool("aborted", true),
+			},
+		},
+		{
+			name:     "with client aborts status",
+			opts:     []ConfigOption{WithClientAborts(499)},
+			canceled: true,
+			wantFields: []slog.Attr{
+				slog.Int("status", 499),
+				slog.Bool("aborted", true),
+			},
+		},
+		{
+			name:     "with client aborts not canceled",
+			opts:     []ConfigOption{WithClientAborts(499)},
+			canceled: false,
+			wantFields: []slog.Attr{
+				slog.Int("status", 200),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), append([]ConfigOption{
+				WithoutIP(),
+				WithoutMethod(),
+				WithoutPath(),
+				WithoutUserAgent(),
+				WithoutLatency(),
+				WithoutBytesIn(),
+				WithoutBytesOut(),
+				WithoutRequestID(),
+			}, tt.opts...)...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			// Create a new request
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.canceled {
+				cancel()
+			} else {
+				defer cancel()
+			}
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(ctx, "GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, 1, handler.Records())
+		})
+	}
+}
+
+func TestNewDeadline(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		wantExceeded bool
+	}{
+		{
+			name:         "deadline not exceeded",
+			timeout:      time.Hour,
+			wantExceeded: false,
+		},
+		{
+			name:         "deadline exceeded",
+			timeout:      -time.Second,
+			wantExceeded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger capturing the deadline group
+			var exceeded, remaining slog.Value
+			logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if len(groups) == 1 && groups[0] == "deadline" {
+						switch a.Key {
+						case "exceeded":
+							exceeded = a.Value
+						case "remaining":
+							remaining = a.Value
+						}
+					}
+					return a
+				},
+			}))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(logger, WithDeadline()))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			// Create a new request
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(ctx, "GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the deadline group
+			require.Equal(t, tt.wantExceeded, exceeded.Bool())
+			require.Equal(t, tt.wantExceeded, remaining.Duration() <= 0)
+		})
+	}
+}
+
+func TestNewControl(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantLevel   slog.Level
+		code        int
+		wantRecords int
+	}{
+		{
+			name:        "default state",
+			body:        `{}`,
+			code:        200,
+			wantLevel:   slog.LevelInfo,
+			wantRecords: 1,
+		},
+		{
+			name:        "blacklist",
+			body:        `{"blacklist": ["^/te"]}`,
+			code:        200,
+			wantLevel:   slog.LevelInfo,
+			wantRecords: 0,
+		},
+		{
+			name:        "level",
+			body:        `{"level": "WARN"}`,
+			code:        200,
+			wantLevel:   slog.LevelInfo,
+			wantRecords: 0,
+		},
+		{
+			name:        "level error",
+			body:        `{"level": "WARN"}`,
+			code:        500,
+			wantLevel:   slog.LevelError,
+			wantRecords: 1,
+		},
+		{
+			name:        "sample rate",
+			body:        `{"sample_rate": 0}`,
+			code:        200,
+			wantLevel:   slog.LevelInfo,
+			wantRecords: 0,
+		},
+		{
+			name:        "sample rate error",
+			body:        `{"sample_rate": 0}`,
+			code:        500,
+			wantLevel:   slog.LevelError,
+			wantRecords: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				tt.wantLevel,
+				[]slog.Attr{slog.Int("status", tt.code)},
+				skipFields,
+			)
+
+			control := NewControl()
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.PUT("/control", ControlHandler(control))
+			router.Use(New(
+				slog.New(handler),
+				WithoutIP(),
+				WithoutMethod(),
+				WithoutPath(),
+				WithoutUserAgent(),
+				WithoutLatency(),
+				WithoutBytesIn(),
+				WithoutBytesOut(),
+				WithoutRequestID(),
+				WithControl(control),
+			))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(tt.code)
+			})
+
+			// Update the control state
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("PUT", "/control", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			// Create a new request
+			resp = httptest.NewRecorder()
+			req, err = http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, tt.wantRecords, handler.Records())
+		})
+	}
+}
+
+func TestControlHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		body      string
+		wantCode  int
+		wantState string
+	}{
+		{
+			name:      "get",
+			method:    "GET",
+			wantCode:  http.StatusOK,
+			wantState: `{"level":"DEBUG","sample_rate":1,"blacklist":[]}`,
+		},
+		{
+			name:      "put",
+			method:    "PUT",
+			body:      `{"level": "INFO", "blacklist": ["^/health$"]}`,
+			wantCode:  http.StatusOK,
+			wantState: `{"level":"INFO","sample_rate":1,"blacklist":["^/health$"]}`,
+		},
+		{
+			name:      "put invalid json",
+			method:    "PUT",
+			body:      `{"level": "VERBOSE"}`,
+			wantCode:  http.StatusBadRequest,
+			wantState: `{"level":"DEBUG","sample_rate":1,"blacklist":[]}`,
+		},
+		{
+			name:      "put invalid regex",
+			method:    "PUT",
+			body:      `{"level": "INFO", "blacklist": ["("]}`,
+			wantCode:  http.StatusBadRequest,
+			wantState: `{"level":"DEBUG","sample_rate":1,"blacklist":[]}`,
+		},
+		{
+			name:      "put invalid sample rate",
+			method:    "PUT",
+			body:      `{"sample_rate": 2}`,
+			wantCode:  http.StatusBadRequest,
+			wantState: `{"level":"DEBUG","sample_rate":1,"blacklist":[]}`,
+		},
+		{
+			name:      "method not allowed",
+			method:    "DELETE",
+			wantCode:  http.StatusMethodNotAllowed,
+			wantState: `{"level":"DEBUG","sample_rate":1,"blacklist":[]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			control := NewControl()
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Any("/control", ControlHandler(control))
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest(tt.method, "/control", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+			require.Equal(t, tt.wantCode, resp.Code)
+
+			// Check the state
+			state, err := json.Marshal(control.State())
+			require.NoError(t, err)
+			require.JSONEq(t, tt.wantState, string(state))
+		})
+	}
+}
+
+func TestController(t *testing.T) {
+	// Create a new logger capturing the level
+	var level slog.Value
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey {
+				level = a.Value
+			}
+			return a
+		},
+	}))
+
+	ctrl := NewController(logger)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ctrl.Handler())
+
+	// Define routes
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// serve sends a request and returns the logged level
+	serve := func() any {
+		level = slog.Value{}
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/test", nil)
+		require.NoError(t, err)
+		router.ServeHTTP(resp, req)
+		return level.Any()
+	}
+	require.Equal(t, slog.LevelInfo, serve())
+
+	// Update the levels
+	require.NoError(t, ctrl.Update(WithHTTPLevels(map[string]slog.Level{"2..": slog.LevelDebug})))
+	require.Equal(t, slog.LevelDebug, serve())
+
+	// Update the filters on top of the levels
+	require.NoError(t, ctrl.Update(WithBlacklistPath([]string{"^/test$"})))
+	require.Nil(t, serve())
+
+	// Invalid updates keep the current config
+	config := ctrl.Config()
+	require.Error(t, ctrl.Update(WithWhitelistPath([]string{"^/other$"})))
+	require.Error(t, ctrl.Update(WithHTTPLevels(map[string]slog.Level{"(": slog.LevelDebug})))
+	require.Same(t, config, ctrl.Config())
+	require.Nil(t, serve())
+}
+
+func TestControllerFlush(t *testing.T) {
+	// Create a new logger writing JSON lines
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	ctrl := NewController(logger, WithDeduplication(time.Hour), WithSummary(time.Hour, true))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ctrl.Handler())
+
+	// Define routes
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// Send a request and two duplicates
+	for i := 0; i < 3; i++ {
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/test", nil)
+		require.NoError(t, err)
+		router.ServeHTTP(resp, req)
+	}
+
+	// Update the config
+	buf.Reset()
+	require.NoError(t, ctrl.Update(WithDefaultLevel(slog.LevelDebug)))
+
+	// Check the duplicates count and the summary are logged
+	records := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		record := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 2)
+	require.Equal(t, "Duplicate requests", records[0]["msg"])
+	require.Equal(t, float64(2), records[0]["count"])
+	require.Equal(t, "Requests summary", records[1]["msg"])
+	require.Equal(t, float64(3), records[1]["requests"])
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		code        int
+		wantErr     bool
+		wantLevel   slog.Level
+		wantFields  []slog.Attr
+		wantRecords int
+	}{
+		{
+			name:        "no variables",
+			env:         map[string]string{},
+			code:        200,
+			wantLevel:   slog.LevelInfo,
+			wantFields:  []slog.Attr{slog.Int("status", 200), slog.String("path", "/test")},
+			wantRecords: 1,
+		},
+		{
+			name:        "level",
+			env:         map[string]string{EnvLevel: "warn"},
+			code:        200,
+			wantLevel:   slog.LevelInfo,
+			wantRecords: 0,
+		},
+		{
+			name:        "level matched",
+			env:         map[string]string{EnvLevel: "WARN"},
+			code:        404,
+			wantLevel:   slog.LevelWarn,
+			wantFields:  []slog.Attr{slog.Int("status", 404), slog.String("path", "/test")},
+			wantRecords: 1,
+		},
+		{
+			name:        "blacklist",
+			env:         map[string]string{EnvBlacklist: "^/health$, ^/test$"},
+			code:        200,
+			wantLevel:   slog.LevelInfo,
+			wantRecords: 0,
+		},
+		{
+			name:        "fields",
+			env:         map[string]string{EnvFields: "method, status"},
+			code:        200,
+			wantLevel:   slog.LevelInfo,
+			wantFields:  []slog.Attr{slog.Int("status", 200), slog.String("method", "GET")},
+			wantRecords: 1,
+		},
+		{
+			name:    "invalid level",
+			env:     map[string]string{EnvLevel: "verbose"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid blacklist",
+			env:     map[string]string{EnvBlacklist: "("},
+			wantErr: true,
+		},
+		{
+			name:    "invalid fields",
+			env:     map[string]string{EnvFields: "status,host"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			// Load the options from the environment
+			opts, err := ConfigFromEnv()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				tt.wantLevel,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), append([]ConfigOption{
+				WithFields([]Field{FieldStatus, FieldPath}),
+			}, opts...)...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(tt.code)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, tt.wantRecords, handler.Records())
+		})
+	}
+}
+
+func TestNewWithConfig(t *testing.T) {
+	warn := slog.LevelWarn
+
+	tests := []struct {
+		name        string
+		settings    Settings
+		json        string
+		wantLevel   slog.Level
+		wantFields  []slog.Attr
+		wantRecords int
+	}{
+		{
+			name:        "fields",
+			settings:    Settings{Fields: []Field{FieldStatus, FieldPath}},
+			wantLevel:   slog.LevelInfo,
+			wantFields:  []slog.Attr{slog.Int("status", 200), slog.String("path", "/test")},
+			wantRecords: 1,
+		},
+		{
+			name: "levels and optional fields",
+			settings: Settings{
+				StatusLevels: []StatusLevel{{Min: 200, Max: 299, Level: slog.LevelDebug}},
+				Fields:       []Field{FieldStatus},
+				Host:         true,
+				Proto:        true,
+			},
+			wantLevel: slog.LevelDebug,
+			wantFields: []slog.Attr{
+				slog.Int("status", 200),
+				slog.String("host", "example.com"),
+				slog.String("proto", "HTTP/1.1"),
+			},
+			wantRecords: 1,
+		},
+		{
+			name:        "min level",
+			settings:    Settings{MinLevel: &warn},
+			wantRecords: 0,
+		},
+		{
+			name:        "json",
+			json:        `{"status_levels": [{"min": 200, "max": 299, "level": "WARN"}], "fields": ["method"], "message": "Request"}`,
+			wantLevel:   slog.LevelWarn,
+			wantFields:  []slog.Attr{slog.String("method", "GET")},
+			wantRecords: 1,
+		},
+		{
+			name:        "json blacklist",
+			json:        `{"blacklist_paths": ["^/test$"]}`,
+			wantRecords: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Decode the settings
+			settings := tt.settings
+			if tt.json != "" {
+				require.NoError(t, json.Unmarshal([]byte(tt.json), &settings))
+			}
+
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}),
+				t,
+				tt.wantLevel,
+				tt.wantFields,
+				skipFields,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(NewWithConfig(slog.New(handler), settings))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "http://example.com/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines
+			require.Equal(t, tt.wantRecords, handler.Records())
+		})
+	}
+}
+
+func TestTryOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		try     func() (ConfigOption, error)
+		wantErr bool
+	}{
+		{
+			name:    "whitelist path",
+			try:     func() (ConfigOption, error) { return TryWithWhitelistPath([]string{"^/test$"}) },
+			wantErr: false,
+		},
+		{
+			name:    "invalid whitelist path",
+			try:     func() (ConfigOption, error) { return TryWithWhitelistPath([]string{"^/test$", "("}) },
+			wantErr: true,
+		},
+		{
+			name:    "blacklist path",
+			try:     func() (ConfigOption, error) { return TryWithBlacklistPath([]string{"^/test$"}) },
+			wantErr: false,
+		},
+		{
+			name:    "invalid blacklist path",
+			try:     func() (ConfigOption, error) { return TryWithBlacklistPath([]string{"["}) },
+			wantErr: true,
+		},
+		{
+			name: "http levels",
+			try: func() (ConfigOption, error) {
+				return TryWithHTTPLevels(map[string]slog.Level{"2..": slog.LevelDebug})
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid http levels",
+			try: func() (ConfigOption, error) {
+				return TryWithHTTPLevels(map[string]slog.Level{"2..": slog.LevelDebug, "5(": slog.LevelError})
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, err := tt.try()
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Nil(t, opt)
+				return
+			}
+			require.NoError(t, err)
+			require.NotPanics(t, func() { NewConfig(opt) })
+		})
+	}
+}
+
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		name              string
+		opts              []ConfigOption
+		path              string
+		wantMessages      []string
+		wantKeys          []string
+		wantPrettyLatency bool
+	}{
+		{
+			name:         "production",
+			opts:         ProductionOptions(),
+			path:         "/test",
+			wantMessages: []string{"Incoming request"},
+			wantKeys:     []string{"ip", "status", "method", "path", "user-agent", "latency", "bytes-in", "bytes-out", "request-id"},
+		},
+		{
+			name:         "production health check",
+			opts:         ProductionOptions(),
+			path:         "/healthz",
+			wantMessages: []string{},
+		},
+		{
+			name:         "development",
+			opts:         DevelopmentOptions(),
+			path:         "/test",
+			wantMessages: []string{"Request started", "Incoming request"},
+			wantKeys: []string{
+				"ip", "status", "method", "path", "user-agent", "latency", "bytes-in", "bytes-out", "request-id",
+				"params", "host", "proto", "content-type", "handler", "ttfb",
+			},
+			wantPrettyLatency: true,
+		},
+		{
+			name:         "minimal",
+			opts:         MinimalOptions(),
+			path:         "/test",
+			wantMessages: []string{"Incoming request"},
+			wantKeys:     []string{"status", "method", "path", "latency"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger writing JSON lines
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(logger, tt.opts...))
+
+			// Define routes
+			router.GET("/*path", func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tt.path, nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the log lines
+			messages := []string{}
+			var record map[string]any
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				if line == "" {
+					continue
+				}
+				record = map[string]any{}
+				require.NoError(t, json.Unmarshal([]byte(line), &record))
+				messages = append(messages, record["msg"].(string))
+			}
+			require.Equal(t, tt.wantMessages, messages)
+			if len(messages) == 0 {
+				return
+			}
+
+			// Check the last log line fields
+			keys := []string{}
+			for k := range record {
+				if k != slog.TimeKey && k != slog.LevelKey && k != slog.MessageKey {
+					keys = append(keys, k)
+				}
+			}
+			require.ElementsMatch(t, tt.wantKeys, keys)
+
+			// Check the latency type
+			_, pretty := record["latency"].(string)
+			require.Equal(t, tt.wantPrettyLatency, pretty)
+		})
+	}
+}
+
+func TestNewCustomLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		wantCalls int
+	}{
+		{
+			name:      "logged request",
+			path:      "/test",
+			wantCalls: 1,
+		},
+		{
+			name:      "excluded request",
+			path:      "/excluded",
+			wantCalls: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				slog.LevelInfo,
+				[]slog.Attr{slog.Int("status", 200)},
+				skipFields,
+			)
+			logger := slog.New(handler)
+
+			// Count the custom logger calls, after the log line is emitted
+			calls := 0
+			customLogger := func(c *gin.Context, l *slog.Logger, level slog.Level, message string, attrs []slog.Attr) {
+				require.Same(t, logger, l)
+				require.Equal(t, 1, handler.Records())
+				require.Equal(t, slog.LevelInfo, level)
+				require.Equal(t, DefaultMessage, message)
+				require.Equal(t, []slog.Attr{slog.Int("status", 200)}, attrs)
+				calls++
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(
+				logger,
+				WithFields([]Field{FieldStatus}),
+				WithBlacklistPathExact([]string{"/excluded"}),
+				WithCustomLogger(customLogger),
+			))
+
+			// Define routes
+			router.GET("/*path", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tt.path, nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of custom logger calls
+			require.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestNewOnLog(t *testing.T) {
+	// Create a new logger with a mock handler
+	handler := slogtest.NewMockHandler(
+		slog.NewTextHandler(os.Stderr, nil),
+		t,
+		slog.LevelWarn,
+		[]slog.Attr{slog.Int("status", 404), slog.String("path", "/test")},
+		skipFields,
+	)
+
+	// Count the requests by status, after the log line is emitted
+	counts := map[string]int{}
+	hooks := []string{}
+	countStatus := func(c *gin.Context, level slog.Level, attrs []slog.Attr) {
+		require.Equal(t, 1, handler.Records())
+		for _, attr := range attrs {
+			if attr.Key == "status" {
+				counts[fmt.Sprintf("%s/%d", level, attr.Value.Int64())]++
+			}
+		}
+		hooks = append(hooks, "count")
+	}
+	annotate := func(c *gin.Context, level slog.Level, attrs []slog.Attr) {
+		hooks = append(hooks, "annotate")
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(New(
+		slog.New(handler),
+		WithFields([]Field{FieldStatus, FieldPath}),
+		WithOnLog(countStatus),
+		WithOnLog(annotate),
+	))
+
+	// Define routes
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	// Create a new request
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/test", nil)
+	require.NoError(t, err)
+	router.ServeHTTP(resp, req)
+
+	// Check the hooks calls
+	require.Equal(t, map[string]int{"WARN/404": 1}, counts)
+	require.Equal(t, []string{"count", "annotate"}, hooks)
+}
+
+func TestNewBeforeLog(t *testing.T) {
+	tests := []struct {
+		name        string
+		beforeLog   []BeforeLog
+		wantLevel   slog.Level
+		wantFields  []slog.Attr
+		wantRecords int
+		wantOnLog   int
+	}{
+		{
+			name:        "without hook",
+			beforeLog:   []BeforeLog{},
+			wantLevel:   slog.LevelWarn,
+			wantFields:  []slog.Attr{slog.Int("status", 404)},
+			wantRecords: 1,
+			wantOnLog:   1,
+		},
+		{
+			name: "cancel",
+			beforeLog: []BeforeLog{
+				func(c *gin.Context, record *Record) bool {
+					return record.Level < slog.LevelWarn
+				},
+			},
+			wantRecords: 0,
+			wantOnLog:   0,
+		},
+		{
+			name: "modify",
+			beforeLog: []BeforeLog{
+				func(c *gin.Context, record *Record) bool {
+					record.Level = slog.LevelInfo
+					return true
+				},
+				func(c *gin.Context, record *Record) bool {
+					record.Attrs = append(record.Attrs, slog.Bool("downgraded", true))
+					return true
+				},
+			},
+			wantLevel:   slog.LevelInfo,
+			wantFields:  []slog.Attr{slog.Int("status", 404), slog.Bool("downgraded", true)},
+			wantRecords: 1,
+			wantOnLog:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(os.Stderr, nil),
+				t,
+				tt.wantLevel,
+				tt.wantFields,
+				skipFields,
+			)
+
+			// Count the post-log hook calls
+			onLog := 0
+			opts := []ConfigOption{
+				WithFields([]Field{FieldStatus}),
+				WithOnLog(func(c *gin.Context, level slog.Level, attrs []slog.Attr) {
+					require.Equal(t, tt.wantLevel, level)
+					require.Equal(t, tt.wantFields, attrs)
+					onLog++
+				}),
+			}
+			for _, beforeLog := range tt.beforeLog {
+				opts = append(opts, WithBeforeLog(beforeLog))
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), opts...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusNotFound)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the number of log lines and hook calls
+			require.Equal(t, tt.wantRecords, handler.Records())
+			require.Equal(t, tt.wantOnLog, onLog)
+		})
+	}
+}
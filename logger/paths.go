@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// pathMatcher checks if a path matches a whitelist or blacklist filter.
+type pathMatcher func(p string) bool
+
+// regexpPathMatcher returns a pathMatcher matching the paths with a regex.
+// It panics if the regex is invalid.
+func regexpPathMatcher(expr string) pathMatcher {
+	return regexp.MustCompile(expr).MatchString
+}
+
+// exactPathMatcher returns a pathMatcher matching the given paths exactly.
+func exactPathMatcher(paths []string) pathMatcher {
+	set := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		set[p] = struct{}{}
+	}
+	return func(p string) bool {
+		_, ok := set[p]
+		return ok
+	}
+}
+
+// globPathMatcher returns a pathMatcher matching the paths with a shell
+// pattern (see path.Match). It panics if the pattern is invalid.
+func globPathMatcher(pattern string) pathMatcher {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic("invalid glob pattern: " + pattern)
+	}
+	return func(p string) bool {
+		matched, _ := path.Match(pattern, p) //nolint: errcheck
+		return matched
+	}
+}
+
+// normalizePath collapses the duplicate slashes, strips the trailing slash
+// and, if enabled, lowercases the path. It returns the path unchanged if the
+// normalization is disabled.
+func (c *Config) normalizePath(p string) string {
+	if !c.normalizePaths {
+		return p
+	}
+	for strings.Contains(p, "//") {
+		p = strings.ReplaceAll(p, "//", "/")
+	}
+	if len(p) > 1 {
+		p = strings.TrimSuffix(p, "/")
+	}
+	if c.lowercasePaths {
+		p = strings.ToLower(p)
+	}
+	return p
+}
@@ -0,0 +1,39 @@
+package logger
+
+import "log/slog"
+
+// ProductionOptions returns the options recommended in production: the health
+// checks are not logged, the gin context errors are logged and the client
+// disconnects are logged with the 499 status.
+func ProductionOptions() []ConfigOption {
+	return []ConfigOption{
+		WithSkipHealthChecks(),
+		WithErrors(),
+		WithClientAborts(499),
+	}
+}
+
+// DevelopmentOptions returns the options recommended in development: more
+// fields are logged, a "Request started" line is logged at DEBUG level and the
+// latencies are human readable.
+func DevelopmentOptions() []ConfigOption {
+	return []ConfigOption{
+		WithRequestStartLog(slog.LevelDebug),
+		WithPrettyLatency(),
+		WithRouteParams(),
+		WithHost(),
+		WithProto(),
+		WithContentType(),
+		WithErrors(),
+		WithHandlerName(),
+		WithTTFB(),
+	}
+}
+
+// MinimalOptions returns the options logging only the status, the method,
+// the path and the latency.
+func MinimalOptions() []ConfigOption {
+	return []ConfigOption{
+		WithFields([]Field{FieldStatus, FieldMethod, FieldPath, FieldLatency}),
+	}
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// clientLimiter limits the number of log lines per client IP address
+// and interval.
+type clientLimiter struct {
+	mu sync.Mutex
+
+	// Maximum number of log lines per client and interval.
+	limit int
+	// Duration of the interval.
+	interval time.Duration
+	// Start of the current interval.
+	windowStart time.Time
+	// Number of requests per client in the current interval.
+	counts map[string]int
+}
+
+// newClientLimiter returns a new clientLimiter.
+func newClientLimiter(limit int, interval time.Duration) *clientLimiter {
+	return &clientLimiter{limit: limit, interval: interval, counts: map[string]int{}}
+}
+
+// allow checks if a log line of the client is allowed. When a new interval
+// starts, it returns the number of suppressed lines per client during the
+// previous interval.
+func (l *clientLimiter) allow(ip string, now time.Time) (bool, map[string]int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Start a new interval and collect the suppressed lines
+	var suppressed map[string]int
+	if now.Sub(l.windowStart) >= l.interval {
+		for client, count := range l.counts {
+			if count > l.limit {
+				if suppressed == nil {
+					suppressed = map[string]int{}
+				}
+				suppressed[client] = count - l.limit
+			}
+		}
+		l.windowStart = now
+		l.counts = map[string]int{}
+	}
+
+	l.counts[ip]++
+	return l.counts[ip] <= l.limit, suppressed
+}
@@ -0,0 +1,13 @@
+package logger
+
+import (
+	"github.com/FabienMht/ginslog/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRequestID returns the request ID set by the logging middleware
+// or the requestid middleware.
+// It returns an empty string if none of the middlewares is used.
+func GetRequestID(c *gin.Context) string {
+	return requestid.Get(c)
+}
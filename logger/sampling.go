@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// adaptiveSampler samples the successful requests to target a maximum number
+// of log lines per second. The sampling rate is computed from the number of
+// requests of the previous second.
+type adaptiveSampler struct {
+	mu sync.Mutex
+
+	// Maximum number of log lines per second.
+	maxPerSecond int
+	// Start of the current one second window.
+	windowStart time.Time
+	// Number of requests seen in the current window.
+	seen int
+	// Number of requests logged in the current window.
+	logged int
+	// Sampling rate computed from the previous window.
+	rate float64
+}
+
+// newAdaptiveSampler returns a new adaptiveSampler.
+func newAdaptiveSampler(maxPerSecond int) *adaptiveSampler {
+	return &adaptiveSampler{maxPerSecond: maxPerSecond, rate: 1}
+}
+
+// sample checks if the request is logged and returns the effective sampling rate.
+func (s *adaptiveSampler) sample(now time.Time) (bool, float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Start a new window and compute the rate from the previous one
+	if elapsed := now.Sub(s.windowStart); elapsed >= time.Second {
+		s.rate = 1
+		if elapsed < 2*time.Second && s.seen > s.maxPerSecond {
+			s.rate = float64(s.maxPerSecond) / float64(s.seen)
+		}
+		s.windowStart = now
+		s.seen = 0
+		s.logged = 0
+	}
+	s.seen++
+
+	// Never exceed the maximum number of log lines per second
+	if s.logged >= s.maxPerSecond || rand.Float64() >= s.rate {
+		return false, s.rate
+	}
+	s.logged++
+	return true, s.rate
+}
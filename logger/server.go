@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// serverErrorWriter forwards the http.Server error log lines to slog.
+type serverErrorWriter struct {
+	// Logger to write to.
+	logger *slog.Logger
+	// Log level to use.
+	level slog.Level
+}
+
+// Write implements io.Writer.
+func (w *serverErrorWriter) Write(p []byte) (int, error) {
+	w.logger.LogAttrs(
+		context.Background(), w.level, "Server error",
+		slog.String("error", strings.TrimSpace(string(p))),
+	)
+	return len(p), nil
+}
+
+// NewServerErrorLog returns a *log.Logger to use as the http.Server ErrorLog.
+// It logs the errors raised by the HTTP server before the request reaches gin
+// (TLS handshake failures, malformed requests, hijacked connections, ...)
+// using slog.
+func NewServerErrorLog(logger *slog.Logger, level slog.Level) *log.Logger {
+	return log.New(&serverErrorWriter{logger: logger, level: level}, "", 0)
+}
@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Settings represents the middleware configuration with exported fields,
+// so it can be built programmatically, serialized, diffed and tested. It is
+// also the logger section of the configuration files, see the config package.
+// The zero value logs the default fields with the default levels.
+// The options without a serializable value (functions, loggers, ...) are
+// only available as ConfigOption.
+type Settings struct {
+	// Log level used when no status level matches.
+	DefaultLevel slog.Level `json:"default_level" yaml:"default-level"`
+	// Minimum level of the logged requests, nil to log all the levels.
+	MinLevel *slog.Level `json:"min_level,omitempty" yaml:"min-level,omitempty"`
+	// Log levels by ranges of HTTP return codes, nil for the default levels.
+	StatusLevels []StatusLevel `json:"status_levels,omitempty" yaml:"status-levels,omitempty"`
+	// Log levels by path regex.
+	PathLevels map[string]slog.Level `json:"path_levels,omitempty" yaml:"path-levels,omitempty"`
+	// Log levels by HTTP method.
+	MethodLevels map[string]slog.Level `json:"method_levels,omitempty" yaml:"method-levels,omitempty"`
+
+	// Regexes of the paths logged.
+	WhitelistPaths []string `json:"whitelist_paths,omitempty" yaml:"whitelist-paths,omitempty"`
+	// Regexes of the paths not logged.
+	BlacklistPaths []string `json:"blacklist_paths,omitempty" yaml:"blacklist-paths,omitempty"`
+	// Regexes of the user agents not logged.
+	BlacklistUserAgents []string `json:"blacklist_user_agents,omitempty" yaml:"blacklist-user-agents,omitempty"`
+	// Skip the health check requests, see WithSkipHealthChecks.
+	SkipHealthChecks bool `json:"skip_health_checks,omitempty" yaml:"skip-health-checks,omitempty"`
+	// Fraction of the successful requests logged, nil to log all of them.
+	SampleRate *float64 `json:"sample_rate,omitempty" yaml:"sample-rate,omitempty"`
+	// Sampling rates by HTTP return code class, see WithClassSampling.
+	SampleRates map[int]float64 `json:"sample_rates,omitempty" yaml:"sample-rates,omitempty"`
+
+	// Message of the log line, empty for DefaultMessage.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Default fields logged, nil for all the default fields.
+	Fields []Field `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Group the default fields, see WithGroups.
+	Groups bool `json:"groups,omitempty" yaml:"groups,omitempty"`
+
+	// Optional fields.
+	RouteParams         bool     `json:"route_params,omitempty" yaml:"route-params,omitempty"`
+	Host                bool     `json:"host,omitempty" yaml:"host,omitempty"`
+	Referer             bool     `json:"referer,omitempty" yaml:"referer,omitempty"`
+	Proto               bool     `json:"proto,omitempty" yaml:"proto,omitempty"`
+	TLS                 bool     `json:"tls,omitempty" yaml:"tls,omitempty"`
+	ContentType         bool     `json:"content_type,omitempty" yaml:"content-type,omitempty"`
+	Errors              bool     `json:"errors,omitempty" yaml:"errors,omitempty"`
+	RemoteAddr          bool     `json:"remote_addr,omitempty" yaml:"remote-addr,omitempty"`
+	ForwardedFor        bool     `json:"forwarded_for,omitempty" yaml:"forwarded-for,omitempty"`
+	HandlerName         bool     `json:"handler_name,omitempty" yaml:"handler-name,omitempty"`
+	Cookies             bool     `json:"cookies,omitempty" yaml:"cookies,omitempty"`
+	TTFB                bool     `json:"ttfb,omitempty" yaml:"ttfb,omitempty"`
+	RequestHeaders      []string `json:"request_headers,omitempty" yaml:"request-headers,omitempty"`
+	ResponseHeaders     []string `json:"response_headers,omitempty" yaml:"response-headers,omitempty"`
+	CorrelationIDHeader string   `json:"correlation_id_header,omitempty" yaml:"correlation-id-header,omitempty"`
+}
+
+// StatusLevel associates a log level to an inclusive range of HTTP return
+// codes, see StatusRange.
+type StatusLevel struct {
+	Min   int        `json:"min" yaml:"min"`
+	Max   int        `json:"max" yaml:"max"`
+	Level slog.Level `json:"level" yaml:"level"`
+}
+
+// Options returns the options of the Settings.
+func (s Settings) Options() []ConfigOption {
+	opts := []ConfigOption{WithDefaultLevel(s.DefaultLevel)}
+
+	// Levels
+	if s.MinLevel != nil {
+		opts = append(opts, WithMinLevel(*s.MinLevel))
+	}
+	if s.StatusLevels != nil {
+		ranges := make([]StatusRange, 0, len(s.StatusLevels))
+		for _, l := range s.StatusLevels {
+			ranges = append(ranges, StatusRange{Min: l.Min, Max: l.Max, Level: l.Level})
+		}
+		opts = append(opts, WithStatusRangeLevels(ranges))
+	}
+	if len(s.PathLevels) > 0 {
+		opts = append(opts, WithPathLevels(s.PathLevels))
+	}
+	if len(s.MethodLevels) > 0 {
+		opts = append(opts, WithMethodLevels(s.MethodLevels))
+	}
+
+	// Filters
+	if len(s.WhitelistPaths) > 0 {
+		opts = append(opts, WithWhitelistPath(s.WhitelistPaths))
+	}
+	if len(s.BlacklistPaths) > 0 {
+		opts = append(opts, WithBlacklistPath(s.BlacklistPaths))
+	}
+	if len(s.BlacklistUserAgents) > 0 {
+		opts = append(opts, WithBlacklistUserAgents(s.BlacklistUserAgents))
+	}
+	if s.SkipHealthChecks {
+		opts = append(opts, WithSkipHealthChecks())
+	}
+	if s.SampleRate != nil {
+		opts = append(opts, WithSampling(*s.SampleRate))
+	}
+	if len(s.SampleRates) > 0 {
+		opts = append(opts, WithClassSampling(s.SampleRates))
+	}
+
+	// Fields
+	if s.Message != "" {
+		opts = append(opts, WithMessage(s.Message))
+	}
+	if s.Fields != nil {
+		opts = append(opts, WithFields(s.Fields))
+	}
+	if s.Groups {
+		opts = append(opts, WithGroups())
+	}
+	for _, field := range []struct {
+		enabled bool
+		opt     func() ConfigOption
+	}{
+		{s.RouteParams, WithRouteParams},
+		{s.Host, WithHost},
+		{s.Referer, WithReferer},
+		{s.Proto, WithProto},
+		{s.TLS, WithTLS},
+		{s.ContentType, WithContentType},
+		{s.Errors, WithErrors},
+		{s.RemoteAddr, WithRemoteAddr},
+		{s.ForwardedFor, WithForwardedFor},
+		{s.HandlerName, WithHandlerName},
+		{s.Cookies, WithCookies},
+		{s.TTFB, WithTTFB},
+	} {
+		if field.enabled {
+			opts = append(opts, field.opt())
+		}
+	}
+	if len(s.RequestHeaders) > 0 {
+		opts = append(opts, WithRequestHeaders(s.RequestHeaders))
+	}
+	if len(s.ResponseHeaders) > 0 {
+		opts = append(opts, WithResponseHeaders(s.ResponseHeaders))
+	}
+	if s.CorrelationIDHeader != "" {
+		opts = append(opts, WithCorrelationID(s.CorrelationIDHeader))
+	}
+
+	return opts
+}
+
+// Validate checks the Settings, so the invalid values are reported as errors
+// instead of panics (e.g. when they are loaded from a file).
+func (s Settings) Validate() error {
+	for _, l := range s.StatusLevels {
+		if l.Min > l.Max {
+			return fmt.Errorf("invalid status level range: %d-%d", l.Min, l.Max)
+		}
+	}
+	for expr := range s.PathLevels {
+		if err := compileRegexps([]string{expr}); err != nil {
+			return fmt.Errorf("invalid path-levels: %w", err)
+		}
+	}
+
+	if len(s.WhitelistPaths) > 0 && len(s.BlacklistPaths) > 0 {
+		return errors.New("whitelist and blacklist can't be used together")
+	}
+	if err := compileRegexps(s.WhitelistPaths); err != nil {
+		return fmt.Errorf("invalid whitelist-paths: %w", err)
+	}
+	if err := compileRegexps(s.BlacklistPaths); err != nil {
+		return fmt.Errorf("invalid blacklist-paths: %w", err)
+	}
+	if err := compileRegexps(s.BlacklistUserAgents); err != nil {
+		return fmt.Errorf("invalid blacklist-user-agents: %w", err)
+	}
+
+	if s.SampleRate != nil && (*s.SampleRate < 0 || *s.SampleRate > 1) {
+		return fmt.Errorf("invalid sample rate %v: must be between 0 and 1", *s.SampleRate)
+	}
+	for class, rate := range s.SampleRates {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("invalid sample rate %v of class %d: must be between 0 and 1", rate, class)
+		}
+	}
+
+	for _, field := range s.Fields {
+		if !isDefaultField(field) {
+			return fmt.Errorf("invalid fields: unknown field %s", field)
+		}
+	}
+	return nil
+}
+
+// NewWithConfig returns a gin.HandlerFunc (middleware) that logs requests
+// using slog with the given Settings, see New. The options are applied on top
+// of the Settings. It panics if the Settings are invalid.
+func NewWithConfig(logger *slog.Logger, settings Settings, opts ...ConfigOption) gin.HandlerFunc {
+	return New(logger, append(settings.Options(), opts...)...)
+}
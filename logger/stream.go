@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isLongLived checks if the request opens a WebSocket or a Server-Sent Events
+// connection.
+func isLongLived(r *http.Request) bool {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return true
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		if strings.Contains(accept, "text/event-stream") {
+			return true
+		}
+	}
+	return false
+}
+
+// countingConn wraps a net.Conn to count the bytes read and written.
+type countingConn struct {
+	net.Conn
+
+	// Number of bytes read.
+	read atomic.Int64
+	// Number of bytes written.
+	written atomic.Int64
+}
+
+// Read implements net.Conn.Read.
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+// Write implements net.Conn.Write.
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.written.Add(int64(n))
+	return n, err
+}
+
+// hijackWriter wraps the gin.ResponseWriter to count the bytes exchanged on
+// the hijacked connection.
+type hijackWriter struct {
+	gin.ResponseWriter
+
+	// Hijacked connection, nil if the connection is not hijacked.
+	conn *countingConn
+}
+
+// Hijack implements http.Hijacker.Hijack.
+func (w *hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.ResponseWriter.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.conn = &countingConn{Conn: conn}
+	return w.conn, rw, nil
+}
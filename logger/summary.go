@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSummarySamples is the maximum number of latencies kept per route to
+// compute the percentiles.
+const maxSummarySamples = 10000
+
+// unmatchedRoute is the route of the requests not matching any route, so the
+// unknown paths (e.g. scanners) are aggregated in a single summary.
+const unmatchedRoute = "<unmatched>"
+
+// routeStats aggregates the requests of a route.
+type routeStats struct {
+	// Route of the requests.
+	route string
+	// Number of requests.
+	requests int
+	// Number of 4XX and 5XX return codes.
+	clientErrors int
+	serverErrors int
+	// Sample of the latencies.
+	latencies []time.Duration
+}
+
+// attrs returns the fields of the route summary.
+func (r *routeStats) attrs() []slog.Attr {
+	slices.Sort(r.latencies)
+	return []slog.Attr{
+		slog.String("route", r.route),
+		slog.Int("requests", r.requests),
+		slog.Int("client-errors", r.clientErrors),
+		slog.Int("server-errors", r.serverErrors),
+		slog.Group("latency",
+			slog.Duration("p50", percentile(r.latencies, 0.5)),
+			slog.Duration("p90", percentile(r.latencies, 0.9)),
+			slog.Duration("p99", percentile(r.latencies, 0.99)),
+		),
+	}
+}
+
+// percentile returns the percentile of sorted latencies.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(latencies)))) - 1
+	return latencies[max(i, 0)]
+}
+
+// summarizer aggregates the requests per route over an interval.
+type summarizer struct {
+	mu sync.Mutex
+
+	// Duration of the interval.
+	interval time.Duration
+	// Log the requests in addition to the summary.
+	requests bool
+	// Start of the current interval.
+	windowStart time.Time
+	// Statistics per route of the current interval.
+	routes map[string]*routeStats
+}
+
+// newSummarizer returns a new summarizer.
+func newSummarizer(interval time.Duration, requests bool) *summarizer {
+	return &summarizer{interval: interval, requests: requests, routes: map[string]*routeStats{}}
+}
+
+// record records a request. When a new interval starts, it returns the
+// statistics per route of the previous interval, sorted by route.
+func (s *summarizer) record(route string, status int, latency time.Duration, now time.Time) []*routeStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Start a new interval
+	var previous []*routeStats
+	if s.windowStart.IsZero() {
+		s.windowStart = now
+	} else if now.Sub(s.windowStart) >= s.interval {
+		previous = s.reset(now)
+	}
+
+	stats, ok := s.routes[route]
+	if !ok {
+		stats = &routeStats{route: route}
+		s.routes[route] = stats
+	}
+	stats.requests++
+	switch {
+	case status >= 500:
+		stats.serverErrors++
+	case status >= 400:
+		stats.clientErrors++
+	}
+
+	// Keep a uniform sample of the latencies
+	if len(stats.latencies) < maxSummarySamples {
+		stats.latencies = append(stats.latencies, latency)
+	} else if i := rand.Intn(stats.requests); i < maxSummarySamples {
+		stats.latencies[i] = latency
+	}
+
+	return previous
+}
+
+// flush returns the statistics per route of the current interval, sorted by
+// route, and starts a new interval.
+func (s *summarizer) flush() []*routeStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reset(time.Time{})
+}
+
+// reset returns the statistics per route sorted by route and starts a new
+// interval at start. It must be called with the lock held.
+func (s *summarizer) reset(start time.Time) []*routeStats {
+	var stats []*routeStats
+	for _, v := range s.routes {
+		stats = append(stats, v)
+	}
+	slices.SortFunc(stats, func(a, b *routeStats) int {
+		return strings.Compare(a.route, b.route)
+	})
+	s.windowStart = start
+	s.routes = map[string]*routeStats{}
+	return stats
+}
+
+// logSummary logs the statistics per route.
+func logSummary(logger *slog.Logger, stats []*routeStats) {
+	for _, v := range stats {
+		logger.LogAttrs(context.Background(), slog.LevelInfo, "Requests summary", v.attrs()...)
+	}
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// userAgentRule associates a name to the user agent tokens identifying it.
+type userAgentRule struct {
+	// Name to log.
+	name string
+	// Tokens to search in the user agent, one is enough to match.
+	tokens []string
+}
+
+// Rules are evaluated in order, the first matching rule wins.
+var (
+	// botTokens identify the bots, crawlers and command line clients.
+	botTokens = []string{
+		"bot", "crawl", "spider", "slurp", "curl/", "wget/", "python-requests",
+		"go-http-client", "kube-probe", "okhttp", "httpclient", "headless",
+	}
+	// browserRules identify the browser.
+	browserRules = []userAgentRule{
+		{name: "Edge", tokens: []string{"Edg/", "Edge/"}},
+		{name: "Opera", tokens: []string{"OPR/", "Opera"}},
+		{name: "Samsung Internet", tokens: []string{"SamsungBrowser/"}},
+		{name: "Chrome", tokens: []string{"Chrome/", "CriOS/"}},
+		{name: "Firefox", tokens: []string{"Firefox/", "FxiOS/"}},
+		{name: "Safari", tokens: []string{"Safari/"}},
+		{name: "Internet Explorer", tokens: []string{"MSIE ", "Trident/"}},
+	}
+	// osRules identify the operating system.
+	osRules = []userAgentRule{
+		{name: "Windows", tokens: []string{"Windows"}},
+		{name: "iOS", tokens: []string{"iPhone", "iPad", "iPod"}},
+		{name: "Android", tokens: []string{"Android"}},
+		{name: "ChromeOS", tokens: []string{"CrOS"}},
+		{name: "macOS", tokens: []string{"Macintosh", "Mac OS X"}},
+		{name: "Linux", tokens: []string{"Linux"}},
+	}
+	// deviceRules identify the device type.
+	deviceRules = []userAgentRule{
+		{name: "tablet", tokens: []string{"iPad", "Tablet"}},
+		{name: "mobile", tokens: []string{"Mobi", "iPhone", "iPod", "Android"}},
+	}
+)
+
+// matchUserAgentRules returns the name of the first matching rule or other.
+func matchUserAgentRules(userAgent string, rules []userAgentRule) string {
+	for _, rule := range rules {
+		for _, token := range rule.tokens {
+			if strings.Contains(userAgent, token) {
+				return rule.name
+			}
+		}
+	}
+	return "other"
+}
+
+// isBot checks if the user agent is a bot, a crawler or a command line client.
+func isBot(userAgent string) bool {
+	if userAgent == "" {
+		return true
+	}
+	lower := strings.ToLower(userAgent)
+	for _, token := range botTokens {
+		if strings.Contains(lower, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// userAgentGroup parses the user agent and returns a group with the browser,
+// the operating system, the device type and the bot classification.
+// It is a lightweight parser based on well-known tokens, not a full database.
+func userAgentGroup(key, userAgent string) slog.Attr {
+	bot := isBot(userAgent)
+	device := "desktop"
+	if bot {
+		device = "bot"
+	} else if d := matchUserAgentRules(userAgent, deviceRules); d != "other" {
+		device = d
+	}
+
+	return slog.Group(key,
+		slog.String("browser", matchUserAgentRules(userAgent, browserRules)),
+		slog.String("os", matchUserAgentRules(userAgent, osRules)),
+		slog.String("device", device),
+		slog.Bool("bot", bot),
+	)
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/FabienMht/ginslog/internal/httpbody"
+	"github.com/gin-gonic/gin"
+)
+
+// responseWriter wraps the gin.ResponseWriter to record the time to first byte
+// and capture the response body.
+type responseWriter struct {
+	gin.ResponseWriter
+
+	// Request start time.
+	start time.Time
+	// Time to first byte, zero if nothing is written yet.
+	ttfb time.Duration
+
+	// Maximum size of the response body to capture, zero to not capture it.
+	bodyMaxSize int
+	// Function checking if the body is captured for the HTTP return code.
+	bodyStatus func(code int) bool
+	// Captured response body.
+	body bytes.Buffer
+	// Response body larger than bodyMaxSize.
+	bodyTruncated bool
+	// Response body is binary, multipart or compressed and is not captured.
+	bodyBinary bool
+}
+
+// newResponseWriter returns a new responseWriter.
+func newResponseWriter(
+	w gin.ResponseWriter, start time.Time, bodyMaxSize int, bodyStatus func(code int) bool,
+) *responseWriter {
+	return &responseWriter{ResponseWriter: w, start: start, bodyMaxSize: bodyMaxSize, bodyStatus: bodyStatus}
+}
+
+// markFirstByte records the time to first byte once.
+func (w *responseWriter) markFirstByte() {
+	if w.ttfb == 0 {
+		w.ttfb = time.Since(w.start)
+	}
+}
+
+// capture captures the response body up to bodyMaxSize.
+func (w *responseWriter) capture(data []byte) {
+	if w.bodyMaxSize == 0 || w.bodyTruncated {
+		return
+	}
+
+	// The status and headers are known when the body is first written
+	if w.body.Len() == 0 {
+		if !w.bodyStatus(w.Status()) {
+			w.bodyMaxSize = 0
+			return
+		}
+		if httpbody.IsBinary(w.Header().Get("Content-Type"), w.Header().Get("Content-Encoding")) {
+			w.bodyMaxSize = 0
+			w.bodyBinary = true
+			return
+		}
+	}
+	if remaining := w.bodyMaxSize - w.body.Len(); len(data) > remaining {
+		data = data[:remaining]
+		w.bodyTruncated = true
+	}
+	w.body.Write(data)
+}
+
+// WriteHeaderNow implements gin.ResponseWriter.
+func (w *responseWriter) WriteHeaderNow() {
+	w.markFirstByte()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Write implements http.ResponseWriter.
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.markFirstByte()
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements gin.ResponseWriter.
+func (w *responseWriter) WriteString(s string) (int, error) {
+	w.markFirstByte()
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	w.markFirstByte()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,15 @@
+package recovery
+
+import (
+	"github.com/FabienMht/ginslog/redact"
+	"github.com/gin-gonic/gin"
+)
+
+// redactBody returns the body with the sensitive values redacted. The form
+// bodies are redacted like a query.
+func redactBody(redactor *redact.Redactor, contentType, body string) string {
+	if contentType == gin.MIMEPOSTForm {
+		return redactor.Query(body)
+	}
+	return redactor.String(body)
+}
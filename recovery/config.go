@@ -3,17 +3,38 @@ package recovery
 import (
 	"log/slog"
 	"net/http"
+	"regexp"
 
+	"github.com/FabienMht/ginslog/redact"
+	"github.com/FabienMht/ginslog/requestid"
 	"github.com/gin-gonic/gin"
 )
 
 // CustomFields allows to add custom fields to the log line.
 type CustomFields func(c *gin.Context) []slog.Attr
 
+// Responder allows to render the HTTP response of a panic.
+type Responder func(c *gin.Context, requestID string, err any)
+
+// CustomLogger is a callback called after the panic is logged, with the logger
+// and the level, the message and the fields of the log line (e.g. to forward
+// the panic to an alerting system). It is optional.
+type CustomLogger func(c *gin.Context, logger *slog.Logger, level slog.Level, message string, attrs []slog.Attr)
+
+// PanicLevels allows to set the log level of a panic from its value.
+type PanicLevels func(err any) slog.Level
+
+// OnPanic is a callback called for each recovered panic with the panic value
+// and the stack trace (e.g. to report the panic to an error tracker or to
+// increment a metric).
+type OnPanic func(c *gin.Context, err any, stack []byte)
+
 // Config represents the recovery middleware configuration.
 type Config struct {
 	// Default log level.
 	defaultLevel slog.Level
+	// Function returning the log level of a panic, nil if not set.
+	panicLevels PanicLevels
 
 	// Custom recevery function.
 	customRecovery gin.RecoveryFunc
@@ -21,6 +42,29 @@ type Config struct {
 	// Custom function to add custom fields to the log line.
 	customFields CustomFields
 
+	// Callback called after the panic is logged, nil if not set.
+	customLogger CustomLogger
+
+	// Callbacks called for each recovered panic.
+	onPanic []OnPanic
+
+	// Attach the panic to the gin context errors.
+	ginError bool
+
+	// Log the http.ErrAbortHandler panics.
+	abortHandlerLog bool
+
+	// Paths to log.
+	whitelistPaths []*regexp.Regexp
+	// Paths to not log.
+	blacklistPaths []*regexp.Regexp
+
+	// Maximum size of the request body dumped, zero to not dump the body.
+	requestBodyMaxSize int
+
+	// Redaction policy of the HTTP request dump.
+	redactor *redact.Redactor
+
 	// Default fields to log.
 	// Error from the panic.
 	errorField bool
@@ -28,19 +72,51 @@ type Config struct {
 	requestField bool
 	// Stack trace.
 	stackField bool
+	// Client IP, method, path, route and user agent.
+	httpFields bool
+	// Request ID set by the logging or the requestid middleware.
+	requestIDField bool
+	// Log the stack trace as a group of frames.
+	structuredStack bool
+	// Function prefixes of the stack frames not logged.
+	stackFilter []string
+	// Maximum number of frames and bytes of the stack trace, zero for no limit.
+	stackMaxFrames int
+	stackMaxBytes  int
+	// Log the type of the panic value.
+	typeField bool
+	// Log the file, the line and the function that panicked.
+	originField bool
 }
 
 // newConfig returns a new Config.
 func newConfig() *Config {
 	return &Config{
 		defaultLevel: slog.LevelError,
+		panicLevels:  nil,
 		customRecovery: func(c *gin.Context, err interface{}) {
 			c.AbortWithStatus(http.StatusInternalServerError)
 		},
-		customFields: nil,
-		errorField:   true,
-		requestField: true,
-		stackField:   true,
+		customFields:       nil,
+		customLogger:       nil,
+		onPanic:            []OnPanic{},
+		ginError:           false,
+		abortHandlerLog:    true,
+		whitelistPaths:     []*regexp.Regexp{},
+		blacklistPaths:     []*regexp.Regexp{},
+		redactor:           redact.New(),
+		requestBodyMaxSize: 0,
+		errorField:         true,
+		requestField:       true,
+		stackField:         true,
+		httpFields:         false,
+		requestIDField:     true,
+		structuredStack:    false,
+		stackFilter:        []string{},
+		stackMaxFrames:     0,
+		stackMaxBytes:      0,
+		typeField:          false,
+		originField:        false,
 	}
 }
 
@@ -48,12 +124,50 @@ func newConfig() *Config {
 func (c *Config) isDefaultFields() bool {
 	return c.errorField ||
 		c.requestField ||
-		c.stackField
+		c.stackField ||
+		c.requestIDField
+}
+
+// panicLevel returns the log level of the panic.
+func (c *Config) panicLevel(err any) slog.Level {
+	if c.panicLevels != nil {
+		return c.panicLevels(err)
+	}
+	return c.defaultLevel
+}
+
+// isExcludedPath checks if the path is excluded by the whitelist or the blacklist.
+func (c *Config) isExcludedPath(path string) bool {
+	// Check if the path is whitelisted
+	if len(c.whitelistPaths) > 0 {
+		whitelisted := false
+		for _, v := range c.whitelistPaths {
+			if v.MatchString(path) {
+				whitelisted = true
+				break
+			}
+		}
+		if !whitelisted {
+			return true
+		}
+	}
+
+	// Check if the path is blacklisted
+	for _, v := range c.blacklistPaths {
+		if v.MatchString(path) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // validate validates the Config.
 func (c *Config) validate() {
-	if !c.isDefaultFields() && c.customFields == nil {
+	if len(c.whitelistPaths) != 0 && len(c.blacklistPaths) != 0 {
+		panic("whitelist and blacklist can't be used together")
+	}
+	if !c.isDefaultFields() && !c.httpFields && !c.typeField && !c.originField && c.customFields == nil {
 		panic("no fields to log")
 	}
 }
@@ -68,6 +182,15 @@ func WithDefaultLevel(level slog.Level) ConfigOption {
 	}
 }
 
+// WithPanicLevels allows to set the log level of the panics from their value,
+// e.g. WARN for the expected panics (validation sentinel errors, ...) and the
+// default level for the unknown ones. It overrides the default level.
+func WithPanicLevels(panicLevels PanicLevels) ConfigOption {
+	return func(c *Config) {
+		c.panicLevels = panicLevels
+	}
+}
+
 // WithCustomRecovery allows to set a custom recovery function.
 func WithCustomRecovery(customRecovery gin.RecoveryFunc) ConfigOption {
 	return func(c *Config) {
@@ -82,12 +205,110 @@ func WithCustomFields(customFields CustomFields) ConfigOption {
 	}
 }
 
+// WithProblemResponse allows to respond to the panics with an RFC 7807
+// application/problem+json body containing the status, a generic title and
+// the request ID, instead of an empty body. It replaces the custom recovery
+// function.
+func WithProblemResponse() ConfigOption {
+	return WithResponder(problemResponse)
+}
+
+// WithResponder allows to render the HTTP response of the panics (custom JSON
+// envelope, HTML error page, ...) with the request ID, see requestid.Get.
+// The request is aborted after the responder is called. It replaces the custom
+// recovery function.
+func WithResponder(responder Responder) ConfigOption {
+	return func(c *Config) {
+		c.customRecovery = func(ctx *gin.Context, err any) {
+			responder(ctx, requestid.Get(ctx), err)
+			ctx.Abort()
+		}
+	}
+}
+
+// WithCustomLogger allows to set a callback called after the panic is logged
+// and before the custom recovery function.
+func WithCustomLogger(customLogger CustomLogger) ConfigOption {
+	return func(c *Config) {
+		c.customLogger = customLogger
+	}
+}
+
+// WithOnPanic allows to add a callback called for each recovered panic, even
+// if its path is not logged, before the custom recovery function. The
+// callbacks are called in the order they are added.
+func WithOnPanic(onPanic OnPanic) ConfigOption {
+	return func(c *Config) {
+		c.onPanic = append(c.onPanic, onPanic)
+	}
+}
+
+// WithGinError allows to attach the panic to the gin context errors before
+// the custom recovery function is called, so the error reporting middlewares
+// and the logger middleware errors field see it.
+func WithGinError() ConfigOption {
+	return func(c *Config) {
+		c.ginError = true
+	}
+}
+
+// WithoutAbortHandlerLog allows to not log the http.ErrAbortHandler panics.
+// By default, they are logged at DEBUG level with the path only.
+func WithoutAbortHandlerLog() ConfigOption {
+	return func(c *Config) {
+		c.abortHandlerLog = false
+	}
+}
+
+// WithWhitelistPath allows to log only the panics of the whitelisted paths.
+// The other panics are recovered without being logged. It panics if the regex
+// is invalid.
+func WithWhitelistPath(whitelistPath []string) ConfigOption {
+	return func(c *Config) {
+		for _, v := range whitelistPath {
+			c.whitelistPaths = append(c.whitelistPaths, regexp.MustCompile(v))
+		}
+	}
+}
+
+// WithBlacklistPath allows to not log the panics of the blacklisted paths
+// (e.g. noisy synthetic test endpoints), they are still recovered. It panics
+// if the regex is invalid.
+func WithBlacklistPath(blacklistPath []string) ConfigOption {
+	return func(c *Config) {
+		for _, v := range blacklistPath {
+			c.blacklistPaths = append(c.blacklistPaths, regexp.MustCompile(v))
+		}
+	}
+}
+
+// WithRedactor allows to set the redaction policy of the HTTP request dump
+// (headers, path, query and body, see WithRequestBody). The form bodies are
+// redacted like a query. By default, only the redact.DefaultHeaders are
+// redacted.
+func WithRedactor(redactor *redact.Redactor) ConfigOption {
+	return func(c *Config) {
+		c.redactor = redactor
+	}
+}
+
+// WithRequestBody allows to add the request body to the request field. Only
+// the first maxSize bytes are dumped and the binary, multipart and compressed
+// bodies are not. The body is read before the handlers and replayed to them.
+// It should be used for debugging purpose in non-production environments.
+func WithRequestBody(maxSize int) ConfigOption {
+	return func(c *Config) {
+		c.requestBodyMaxSize = maxSize
+	}
+}
+
 // WithoutDefaultFields to not use the default fields in the log line.
 func WithoutDefaultFields() ConfigOption {
 	return func(c *Config) {
 		c.errorField = false
 		c.requestField = false
 		c.stackField = false
+		c.requestIDField = false
 	}
 }
 
@@ -111,3 +332,69 @@ func WithoutStack() ConfigOption {
 		c.stackField = false
 	}
 }
+
+// WithPanicType allows to log the Go type of the panic value in the panic
+// group (panic.type), e.g. string or *errors.errorString, to classify the
+// panics.
+func WithPanicType() ConfigOption {
+	return func(c *Config) {
+		c.typeField = true
+	}
+}
+
+// WithPanicOrigin allows to log the file, the line and the function of the
+// frame that panicked in the panic group (panic.file, panic.line and
+// panic.func), so the alerts can point to the code without parsing the stack.
+// The Go runtime frames are skipped.
+func WithPanicOrigin() ConfigOption {
+	return func(c *Config) {
+		c.originField = true
+	}
+}
+
+// WithHTTPFields allows to add the client IP, the HTTP method, path, route and
+// user agent to the log line (ip, method, path, route and user-agent fields),
+// like the logging middleware, so the panics can be searched without parsing
+// the request field. The path is redacted.
+func WithHTTPFields() ConfigOption {
+	return func(c *Config) {
+		c.httpFields = true
+	}
+}
+
+// WithoutRequestID to not log the request-id field.
+func WithoutRequestID() ConfigOption {
+	return func(c *Config) {
+		c.requestIDField = false
+	}
+}
+
+// WithStackFilter allows to not log the stack frames whose function starts with
+// one of the prefixes (e.g. DefaultStackFilter), so the stack starts at the
+// application code. The stack starts at the frame that panicked.
+func WithStackFilter(prefixes []string) ConfigOption {
+	return func(c *Config) {
+		c.stackFilter = append(c.stackFilter, prefixes...)
+	}
+}
+
+// WithStackLimits allows to bound the stack trace to maxFrames frames and
+// maxBytes bytes, zero for no limit, so the log lines don't exceed the log
+// pipeline size limits. The debug.Stack string is truncated to maxBytes, the
+// frames are dropped from the bottom of the stack otherwise. At most 64 frames
+// are captured when maxFrames is set.
+func WithStackLimits(maxFrames, maxBytes int) ConfigOption {
+	return func(c *Config) {
+		c.stackMaxFrames = maxFrames
+		c.stackMaxBytes = maxBytes
+	}
+}
+
+// WithStructuredStack allows to log the stack field as a group of the frames
+// (function, file and line) starting at the frame that panicked, instead of
+// the debug.Stack string, so the log backends can display and group them.
+func WithStructuredStack() ConfigOption {
+	return func(c *Config) {
+		c.structuredStack = true
+	}
+}
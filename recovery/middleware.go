@@ -2,16 +2,28 @@ package recovery
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"net/http/httputil"
 	"runtime/debug"
 
+	"github.com/FabienMht/ginslog/internal/httpbody"
+	"github.com/FabienMht/ginslog/requestid"
 	"github.com/gin-gonic/gin"
 )
 
+// panicMessage is the message of the panic log line.
+const panicMessage = "Panic recovered"
+
 // New returns a gin.HandlerFunc (middleware) that recovers from any
 // panics and logs the panic using slog. It sets the HTTP status code to
-// 500. By default, the log level is ERROR.
+// 500. By default, the log level is ERROR and the request ID set by the logging
+// or the requestid middleware is logged in the request-id field.
+//
+// The http.ErrAbortHandler panics are not recovered, so net/http aborts the
+// response, they are only logged at DEBUG level.
 func New(logger *slog.Logger, opts ...ConfigOption) gin.HandlerFunc {
 	config := newConfig()
 	for _, opt := range opts {
@@ -20,39 +32,52 @@ func New(logger *slog.Logger, opts ...ConfigOption) gin.HandlerFunc {
 	config.validate()
 
 	return func(c *gin.Context) {
+		// Capture the request body
+		// Binary, multipart and compressed bodies are not read
+		var requestBody string
+		if config.requestField && config.requestBodyMaxSize > 0 &&
+			c.Request.Body != nil && c.Request.Body != http.NoBody &&
+			!httpbody.IsBinary(c.ContentType(), c.GetHeader("Content-Encoding")) {
+			body, replay, truncated, err := httpbody.Peek(c.Request.Body, config.requestBodyMaxSize)
+			c.Request.Body = replay
+			if err == nil {
+				requestBody = httpbody.Format(body, truncated)
+			}
+		}
+
 		defer func() {
 			if err := recover(); err != nil {
-				var httpRequest []byte
+				excluded := config.isExcludedPath(c.Request.URL.Path)
 
-				if config.isDefaultFields() {
-					httpRequest, _ = httputil.DumpRequest(c.Request, false) //nolint: errcheck
+				// Panic again to abort the handler as intended by net/http
+				if isAbortHandler(err) {
+					if config.abortHandlerLog && !excluded {
+						logger.LogAttrs(
+							context.Background(), slog.LevelDebug, "Handler aborted",
+							slog.String("path", config.redactor.Path(c.Request.URL.Path)),
+						)
+					}
+					panic(err)
 				}
 
-				attributes := []slog.Attr{}
-
-				// Add the error
-				if config.errorField {
-					attributes = append(attributes, slog.Any("error", err))
+				// Log the panic unless the path is excluded
+				if !excluded {
+					logPanic(c, logger, config, err, requestBody)
 				}
 
-				// Add the request
-				if config.requestField {
-					attributes = append(attributes, slog.String("request", string(httpRequest)))
+				// Call the panic callbacks
+				if len(config.onPanic) > 0 {
+					stack := debug.Stack()
+					for _, onPanic := range config.onPanic {
+						onPanic(c, err, stack)
+					}
 				}
 
-				// Add the stack trace
-				if config.stackField {
-					attributes = append(attributes, slog.String("stack", string(debug.Stack())))
+				// Attach the panic to the gin context errors
+				if config.ginError {
+					_ = c.Error(panicError(err)) //nolint: errcheck
 				}
 
-				// Add custom fields
-				if config.customFields != nil {
-					attributes = append(attributes, config.customFields(c)...)
-				}
-
-				// Log the panic
-				logger.LogAttrs(context.Background(), config.defaultLevel, "Panic recovered", attributes...)
-
 				// Call the custom recovery
 				config.customRecovery(c, err)
 			}
@@ -60,3 +85,87 @@ func New(logger *slog.Logger, opts ...ConfigOption) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// isAbortHandler checks if the panic is http.ErrAbortHandler.
+func isAbortHandler(err any) bool {
+	e, ok := err.(error)
+	return ok && errors.Is(e, http.ErrAbortHandler)
+}
+
+// panicError returns an error wrapping the panic value.
+func panicError(err any) error {
+	if e, ok := err.(error); ok {
+		return fmt.Errorf("panic recovered: %w", e)
+	}
+	return fmt.Errorf("panic recovered: %v", err)
+}
+
+// logPanic logs the recovered panic with the captured request body.
+func logPanic(c *gin.Context, logger *slog.Logger, config *Config, err any, requestBody string) {
+	var httpRequest []byte
+
+	if config.isDefaultFields() {
+		httpRequest, _ = httputil.DumpRequest(config.redactor.Request(c.Request), false) //nolint: errcheck
+	}
+
+	attributes := []slog.Attr{}
+
+	// Add the error
+	if config.errorField {
+		attributes = append(attributes, slog.Any("error", err))
+	}
+
+	// Add the request
+	if config.requestField {
+		attributes = append(attributes, slog.String("request", string(httpRequest)+redactBody(config.redactor, c.ContentType(), requestBody)))
+	}
+
+	// Add the HTTP fields
+	if config.httpFields {
+		attributes = append(attributes,
+			slog.String("ip", c.ClientIP()),
+			slog.String("method", c.Request.Method),
+			slog.String("path", config.redactor.Path(c.Request.URL.Path)),
+			slog.String("route", c.FullPath()),
+			slog.String("user-agent", c.Request.UserAgent()),
+		)
+	}
+
+	// Add the request ID to join the panic to the request log line
+	if config.requestIDField {
+		if requestID := requestid.Get(c); requestID != "" {
+			attributes = append(attributes, slog.String("request-id", requestID))
+		}
+	}
+
+	// Add the stack trace
+	if config.stackField {
+		attributes = append(attributes, config.stackAttr("stack"))
+	}
+
+	// Add the panic type and origin
+	if config.typeField || config.originField {
+		panicAttrs := []any{}
+		if config.typeField {
+			panicAttrs = append(panicAttrs, slog.String("type", fmt.Sprintf("%T", err)))
+		}
+		if config.originField {
+			panicAttrs = append(panicAttrs, originAttrs()...)
+		}
+		attributes = append(attributes, slog.Group("panic", panicAttrs...))
+	}
+
+	// Add custom fields
+	if config.customFields != nil {
+		attributes = append(attributes, config.customFields(c)...)
+	}
+
+	// Log the panic
+	level := config.panicLevel(err)
+	logger.LogAttrs(context.Background(), level, panicMessage, attributes...)
+
+	// Call the custom logger
+	if config.customLogger != nil {
+		config.customLogger(c, logger, level, panicMessage, attributes)
+	}
+}
@@ -1,12 +1,20 @@
 package recovery
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/FabienMht/ginslog/internal/httpbody"
+	"github.com/FabienMht/ginslog/redact"
+	"github.com/FabienMht/ginslog/requestid"
 	"github.com/FabienMht/ginslog/slogtest"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -169,3 +177,931 @@ func TestNewRecovery(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRedaction(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		wantRequest []string
+	}{
+		{
+			name:        "default redactor",
+			opts:        []ConfigOption{},
+			wantRequest: []string{"GET /test?token=secret", "Authorization: [REDACTED]", "X-Api-Key: [REDACTED]", "X-Api-Version: 2"},
+		},
+		{
+			name: "with redactor",
+			opts: []ConfigOption{
+				WithRedactor(redact.New(
+					redact.WithoutDefaultHeaders(),
+					redact.WithHeaders([]string{"x-api-version"}),
+					redact.WithQueryKeys([]string{"^token$"}),
+				)),
+			},
+			wantRequest: []string{"GET /test?token=[REDACTED]", "Authorization: Bearer token", "X-Api-Key: secret", "X-Api-Version: [REDACTED]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger writing the request dump to a buffer
+			var request string
+			logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if a.Key == "request" {
+						request = a.Value.String()
+					}
+					return a
+				},
+			}))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(logger, tt.opts...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				panic("test")
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test?token=secret", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("X-Api-Key", "secret")
+			req.Header.Set("X-Api-Version", "2")
+			router.ServeHTTP(resp, req)
+
+			// Check the request dump
+			for _, want := range tt.wantRequest {
+				require.Contains(t, request, want)
+			}
+
+			// Check the request headers are not modified
+			require.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestNewBodyRedaction(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantBody    string
+	}{
+		{
+			name:        "form body",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "user=test&password=secret",
+			wantBody:    "user=test&password=[REDACTED]",
+		},
+		{
+			name:        "json body",
+			contentType: "application/json",
+			body:        `{"email":"test@example.com"}`,
+			wantBody:    `{"email":"[REDACTED]"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger capturing the fields
+			handler := &captureHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), WithRequestBody(1024), WithRedactor(redact.New(
+				redact.WithQueryKeys([]string{"^password$"}),
+				redact.WithValuePatterns([]string{redact.EmailPattern}),
+			))))
+
+			// Define routes
+			router.POST("/test", panicHandler)
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/test", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tt.contentType)
+			router.ServeHTTP(resp, req)
+
+			// Check the dumped body is redacted
+			require.True(t, strings.HasSuffix(handler.attrs["request"].String(), "\r\n\r\n"+tt.wantBody))
+		})
+	}
+}
+
+func TestNewCustomLogger(t *testing.T) {
+	// Create a new logger with a mock handler
+	handler := slogtest.NewMockHandler(
+		slog.NewTextHandler(io.Discard, nil),
+		t,
+		slog.LevelWarn,
+		[]slog.Attr{slog.Any("error", "test")},
+		nil,
+	)
+	logger := slog.New(handler)
+
+	// Record the custom logger and custom recovery calls
+	calls := []string{}
+	customLogger := func(c *gin.Context, l *slog.Logger, level slog.Level, message string, attrs []slog.Attr) {
+		require.Same(t, logger, l)
+		require.Equal(t, 1, handler.Records())
+		require.Equal(t, slog.LevelWarn, level)
+		require.Equal(t, "Panic recovered", message)
+		require.Equal(t, []slog.Attr{slog.Any("error", "test")}, attrs)
+		calls = append(calls, "logger")
+	}
+	customRecovery := func(c *gin.Context, err interface{}) {
+		calls = append(calls, "recovery")
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(New(
+		logger,
+		WithDefaultLevel(slog.LevelWarn),
+		WithoutRequest(),
+		WithoutStack(),
+		WithCustomLogger(customLogger),
+		WithCustomRecovery(customRecovery),
+	))
+
+	// Define routes
+	router.GET("/test", func(c *gin.Context) {
+		panic("test")
+	})
+
+	// Create a new request
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/test", nil)
+	require.NoError(t, err)
+	router.ServeHTTP(resp, req)
+
+	// Check the calls order
+	require.Equal(t, []string{"logger", "recovery"}, calls)
+}
+
+func TestNewPathFilters(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		path        string
+		wantRecords int
+		wantPanic   bool
+	}{
+		{
+			name:        "whitelisted path",
+			opts:        []ConfigOption{WithWhitelistPath([]string{"^/api/"})},
+			path:        "/api/test",
+			wantRecords: 1,
+		},
+		{
+			name:        "not whitelisted path",
+			opts:        []ConfigOption{WithWhitelistPath([]string{"^/api/"})},
+			path:        "/synthetic",
+			wantRecords: 0,
+		},
+		{
+			name:        "blacklisted path",
+			opts:        []ConfigOption{WithBlacklistPath([]string{"^/synthetic$"})},
+			path:        "/synthetic",
+			wantRecords: 0,
+		},
+		{
+			name:        "not blacklisted path",
+			opts:        []ConfigOption{WithBlacklistPath([]string{"^/synthetic$"})},
+			path:        "/api/test",
+			wantRecords: 1,
+		},
+		{
+			name: "whitelist and blacklist",
+			opts: []ConfigOption{
+				WithWhitelistPath([]string{"^/api/"}),
+				WithBlacklistPath([]string{"^/synthetic$"}),
+			},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(io.Discard, nil),
+				t,
+				slog.LevelError,
+				[]slog.Attr{slog.Any("error", "test")},
+				nil,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			opts := append([]ConfigOption{WithoutRequest(), WithoutStack()}, tt.opts...)
+			if tt.wantPanic {
+				require.Panics(t, func() { New(slog.New(handler), opts...) })
+				return
+			}
+			router.Use(New(slog.New(handler), opts...))
+
+			// Define routes
+			router.GET("/*path", func(c *gin.Context) {
+				panic("test")
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tt.path, nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the panic is recovered and logged
+			require.Equal(t, http.StatusInternalServerError, resp.Code)
+			require.Equal(t, tt.wantRecords, handler.Records())
+		})
+	}
+}
+
+func TestNewAbortHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		wantRecords int
+	}{
+		{
+			name:        "default options",
+			opts:        []ConfigOption{},
+			wantRecords: 1,
+		},
+		{
+			name:        "without abort handler log",
+			opts:        []ConfigOption{WithoutAbortHandlerLog()},
+			wantRecords: 0,
+		},
+		{
+			name:        "blacklisted path",
+			opts:        []ConfigOption{WithBlacklistPath([]string{"^/test$"})},
+			wantRecords: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger with a mock handler
+			handler := slogtest.NewMockHandler(
+				slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}),
+				t,
+				slog.LevelDebug,
+				[]slog.Attr{slog.String("path", "/test")},
+				nil,
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), tt.opts...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				panic(http.ErrAbortHandler)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+
+			// Check the panic is not recovered
+			require.PanicsWithValue(t, http.ErrAbortHandler, func() { router.ServeHTTP(resp, req) })
+			require.Equal(t, tt.wantRecords, handler.Records())
+		})
+	}
+}
+
+func TestNewGinError(t *testing.T) {
+	errTest := errors.New("test")
+
+	tests := []struct {
+		name        string
+		opts        []ConfigOption
+		panic       any
+		wantErrors  []string
+		wantWrapped bool
+	}{
+		{
+			name:       "default options",
+			opts:       []ConfigOption{},
+			panic:      "test",
+			wantErrors: nil,
+		},
+		{
+			name:       "with gin error",
+			opts:       []ConfigOption{WithGinError()},
+			panic:      "test",
+			wantErrors: []string{"panic recovered: test"},
+		},
+		{
+			name:        "with gin error wrapping an error",
+			opts:        []ConfigOption{WithGinError()},
+			panic:       errTest,
+			wantErrors:  []string{"panic recovered: test"},
+			wantWrapped: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			// Capture the gin context errors after the recovery
+			var ginErrors []string
+			var wrapped bool
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Next()
+				ginErrors = c.Errors.Errors()
+				wrapped = len(c.Errors) > 0 && errors.Is(c.Errors.Last(), errTest)
+			})
+			router.Use(New(logger, tt.opts...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				panic(tt.panic)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the gin context errors
+			require.Equal(t, http.StatusInternalServerError, resp.Code)
+			require.Equal(t, tt.wantErrors, ginErrors)
+			require.Equal(t, tt.wantWrapped, wrapped)
+		})
+	}
+}
+
+func TestNewProblemResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID bool
+		wantBody  string
+	}{
+		{
+			name:      "with request ID",
+			requestID: true,
+			wantBody:  `{"type":"about:blank","title":"Internal Server Error","status":500,"request_id":"test-id"}`,
+		},
+		{
+			name:      "without request ID",
+			requestID: false,
+			wantBody:  `{"type":"about:blank","title":"Internal Server Error","status":500}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			if tt.requestID {
+				router.Use(requestid.New(requestid.WithTrustHeader()))
+			}
+			router.Use(New(logger, WithProblemResponse()))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				panic("test")
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			req.Header.Set(requestid.HeaderRequestID, "test-id")
+			router.ServeHTTP(resp, req)
+
+			// Check the response
+			require.Equal(t, http.StatusInternalServerError, resp.Code)
+			require.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+			require.JSONEq(t, tt.wantBody, resp.Body.String())
+		})
+	}
+}
+
+func TestNewResponder(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// Render a custom JSON envelope
+	responder := func(c *gin.Context, requestID string, err any) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":      fmt.Sprint(err),
+			"request-id": requestID,
+		})
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestid.New(requestid.WithTrustHeader()))
+	router.Use(New(logger, WithResponder(responder)))
+
+	// Define routes
+	router.GET("/test", func(c *gin.Context) {
+		panic("test")
+	})
+
+	// Create a new request
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/test", nil)
+	require.NoError(t, err)
+	req.Header.Set(requestid.HeaderRequestID, "test-id")
+	router.ServeHTTP(resp, req)
+
+	// Check the response
+	require.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	require.JSONEq(t, `{"error":"test","request-id":"test-id"}`, resp.Body.String())
+}
+
+func TestNewStructuredStack(t *testing.T) {
+	// Create a new logger capturing the fields
+	handler := &captureHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+	logger := slog.New(handler)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(New(logger, WithStructuredStack()))
+
+	// Define routes
+	router.GET("/test", panicHandler)
+
+	// Create a new request
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/test", nil)
+	require.NoError(t, err)
+	router.ServeHTTP(resp, req)
+
+	// Check the first frame is the frame that panicked
+	stack := handler.attrs["stack"]
+	require.Equal(t, slog.KindGroup, stack.Kind())
+	frames := stack.Group()
+	require.NotEmpty(t, frames)
+	require.Equal(t, "0", frames[0].Key)
+	frame := frames[0].Value.Group()
+	require.Equal(t, "function", frame[0].Key)
+	require.Equal(t, "github.com/FabienMht/ginslog/recovery.panicHandler", frame[0].Value.String())
+	require.Equal(t, "file", frame[1].Key)
+	require.True(t, strings.HasSuffix(frame[1].Value.String(), "recovery/middleware_test.go"))
+	require.Equal(t, "line", frame[2].Key)
+	require.Positive(t, frame[2].Value.Int64())
+}
+
+// captureHandler is a slog handler capturing the level and the fields of the
+// last record.
+type captureHandler struct {
+	slog.Handler
+	level slog.Level
+	attrs map[string]slog.Value
+}
+
+// Handle implements Handler.Handle.
+func (h *captureHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.level = r.Level
+	h.attrs = map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		h.attrs[a.Key] = a.Value
+		return true
+	})
+	return h.Handler.Handle(ctx, r)
+}
+
+// panicHandler is a gin handler panicking.
+func panicHandler(c *gin.Context) {
+	panic("test")
+}
+
+func TestNewStackFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ConfigOption
+	}{
+		{
+			name: "string stack",
+			opts: []ConfigOption{WithStackFilter(DefaultStackFilter), WithStackFilter([]string{"testing."})},
+		},
+		{
+			name: "structured stack",
+			opts: []ConfigOption{WithStackFilter(DefaultStackFilter), WithStackFilter([]string{"testing."}), WithStructuredStack()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger capturing the fields
+			handler := &captureHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), tt.opts...))
+
+			// Define routes
+			router.GET("/test", panicHandler)
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Collect the functions of the stack
+			functions := []string{}
+			stack := handler.attrs["stack"]
+			if stack.Kind() == slog.KindGroup {
+				for _, frame := range stack.Group() {
+					functions = append(functions, frame.Value.Group()[0].Value.String())
+				}
+			} else {
+				for _, line := range strings.Split(stack.String(), "\n") {
+					if line != "" && !strings.HasPrefix(line, "\t") {
+						functions = append(functions, strings.TrimSuffix(line, "()"))
+					}
+				}
+			}
+
+			// Check only the application frames are logged
+			require.Equal(t, []string{
+				"github.com/FabienMht/ginslog/recovery.panicHandler",
+				"github.com/FabienMht/ginslog/recovery.TestNewStackFilter.func1",
+			}, functions)
+		})
+	}
+}
+
+func TestNewStackLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []ConfigOption
+		wantFrames int
+		wantBytes  int
+	}{
+		{
+			name:      "debug stack truncated",
+			opts:      []ConfigOption{WithStackLimits(0, 100)},
+			wantBytes: 100 + len(stackTruncated),
+		},
+		{
+			name:       "max frames",
+			opts:       []ConfigOption{WithStackLimits(2, 0), WithStructuredStack()},
+			wantFrames: 2,
+		},
+		{
+			name:      "max bytes",
+			opts:      []ConfigOption{WithStackLimits(0, 200), WithStackFilter(DefaultStackFilter)},
+			wantBytes: 200,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger capturing the fields
+			handler := &captureHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), tt.opts...))
+
+			// Define routes
+			router.GET("/test", panicHandler)
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the stack limits
+			stack := handler.attrs["stack"]
+			if tt.wantFrames > 0 {
+				require.Len(t, stack.Group(), tt.wantFrames)
+			}
+			if tt.wantBytes > 0 {
+				require.NotEmpty(t, stack.String())
+				require.LessOrEqual(t, len(stack.String()), tt.wantBytes)
+			}
+		})
+	}
+}
+
+func TestNewPanicOrigin(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  gin.HandlerFunc
+		wantFunc string
+	}{
+		{
+			name:     "panic",
+			handler:  panicHandler,
+			wantFunc: "github.com/FabienMht/ginslog/recovery.panicHandler",
+		},
+		{
+			name: "runtime error",
+			handler: func(c *gin.Context) {
+				var m map[string]int
+				m["test"] = 1
+			},
+			wantFunc: "github.com/FabienMht/ginslog/recovery.TestNewPanicOrigin.func1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger capturing the fields
+			handler := &captureHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), WithoutDefaultFields(), WithPanicOrigin()))
+
+			// Define routes
+			router.GET("/test", tt.handler)
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the panic origin
+			origin := handler.attrs["panic"].Group()
+			require.Len(t, origin, 3)
+			require.Equal(t, "file", origin[0].Key)
+			require.True(t, strings.HasSuffix(origin[0].Value.String(), "recovery/middleware_test.go"))
+			require.Equal(t, "line", origin[1].Key)
+			require.Positive(t, origin[1].Value.Int64())
+			require.Equal(t, "func", origin[2].Key)
+			require.Equal(t, tt.wantFunc, origin[2].Value.String())
+			require.NotContains(t, handler.attrs, "stack")
+		})
+	}
+}
+
+func TestNewRequestBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxSize     int
+		wantBody    string
+	}{
+		{
+			name:        "json body",
+			contentType: "application/json",
+			body:        `{"test":"test"}`,
+			maxSize:     1024,
+			wantBody:    `{"test":"test"}`,
+		},
+		{
+			name:        "truncated body",
+			contentType: "text/plain",
+			body:        "test body",
+			maxSize:     4,
+			wantBody:    "test" + httpbody.TruncatedSuffix,
+		},
+		{
+			name:        "binary body",
+			contentType: "application/octet-stream",
+			body:        "test body",
+			maxSize:     1024,
+			wantBody:    "",
+		},
+		{
+			name:        "no body",
+			contentType: "application/json",
+			body:        `{"test":"test"}`,
+			maxSize:     0,
+			wantBody:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger capturing the fields
+			handler := &captureHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), WithRequestBody(tt.maxSize)))
+
+			// Define routes
+			var handlerBody []byte
+			router.POST("/test", func(c *gin.Context) {
+				handlerBody, _ = io.ReadAll(c.Request.Body)
+				panic("test")
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/test", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tt.contentType)
+			router.ServeHTTP(resp, req)
+
+			// Check the body is dumped after the headers and replayed to the handler
+			request := handler.attrs["request"].String()
+			require.True(t, strings.HasSuffix(request, "\r\n\r\n"+tt.wantBody), request)
+			require.Equal(t, tt.body, string(handlerBody))
+		})
+	}
+}
+
+// errValidation is an expected panic value.
+var errValidation = errors.New("validation error")
+
+func TestNewPanicLevels(t *testing.T) {
+	tests := []struct {
+		name      string
+		panic     any
+		wantLevel slog.Level
+		wantType  string
+	}{
+		{
+			name:      "expected panic",
+			panic:     fmt.Errorf("test: %w", errValidation),
+			wantLevel: slog.LevelWarn,
+			wantType:  "*fmt.wrapError",
+		},
+		{
+			name:      "unknown panic",
+			panic:     "test",
+			wantLevel: slog.LevelError,
+			wantType:  "string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger capturing the fields
+			handler := &captureHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(slog.New(handler), WithPanicType(), WithPanicLevels(func(err any) slog.Level {
+				if e, ok := err.(error); ok && errors.Is(e, errValidation) {
+					return slog.LevelWarn
+				}
+				return slog.LevelError
+			})))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				panic(tt.panic)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the level and the panic type
+			require.Equal(t, tt.wantLevel, handler.level)
+			require.Equal(t, []slog.Attr{slog.String("type", tt.wantType)}, handler.attrs["panic"].Group())
+		})
+	}
+}
+
+func TestNewOnPanic(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ConfigOption
+	}{
+		{
+			name: "logged path",
+			opts: []ConfigOption{},
+		},
+		{
+			name: "excluded path",
+			opts: []ConfigOption{WithBlacklistPath([]string{"^/test$"})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Record the callbacks calls
+			calls := []string{}
+			onPanic := func(name string) OnPanic {
+				return func(c *gin.Context, err any, stack []byte) {
+					require.Equal(t, "test", err)
+					require.Contains(t, string(stack), "recovery.panicHandler")
+					calls = append(calls, name)
+				}
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			opts := append(tt.opts, WithOnPanic(onPanic("first")), WithOnPanic(onPanic("second")))
+			router.Use(New(slog.New(slog.NewTextHandler(io.Discard, nil)), opts...))
+
+			// Define routes
+			router.GET("/test", panicHandler)
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the callbacks are called in order
+			require.Equal(t, []string{"first", "second"}, calls)
+			require.Equal(t, http.StatusInternalServerError, resp.Code)
+		})
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestID     bool
+		opts          []ConfigOption
+		wantRequestID string
+	}{
+		{
+			name:          "with requestid middleware",
+			requestID:     true,
+			opts:          []ConfigOption{},
+			wantRequestID: "test-id",
+		},
+		{
+			name:          "without requestid middleware",
+			requestID:     false,
+			opts:          []ConfigOption{},
+			wantRequestID: "",
+		},
+		{
+			name:          "without request ID",
+			requestID:     true,
+			opts:          []ConfigOption{WithoutRequestID()},
+			wantRequestID: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new logger capturing the fields
+			handler := &captureHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			if tt.requestID {
+				router.Use(requestid.New(requestid.WithTrustHeader()))
+			}
+			router.Use(New(slog.New(handler), tt.opts...))
+
+			// Define routes
+			router.GET("/test", panicHandler)
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			req.Header.Set(requestid.HeaderRequestID, "test-id")
+			router.ServeHTTP(resp, req)
+
+			// Check the request ID
+			requestID, ok := handler.attrs["request-id"]
+			require.Equal(t, tt.wantRequestID != "", ok)
+			if ok {
+				require.Equal(t, tt.wantRequestID, requestID.String())
+			}
+		})
+	}
+}
+
+func TestNewHTTPFields(t *testing.T) {
+	// Create a new logger with a mock handler
+	handler := slogtest.NewMockHandler(
+		slog.NewTextHandler(io.Discard, nil),
+		t,
+		slog.LevelError,
+		[]slog.Attr{
+			slog.String("ip", "192.0.2.1"),
+			slog.String("method", "GET"),
+			slog.String("path", "/users/[REDACTED]"),
+			slog.String("route", "/users/:email"),
+			slog.String("user-agent", "test"),
+		},
+		[]string{},
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(New(slog.New(handler), WithoutDefaultFields(), WithHTTPFields(), WithRedactor(redact.New(
+		redact.WithValuePatterns([]string{redact.EmailPattern}),
+	))))
+
+	// Define routes
+	router.GET("/users/:email", panicHandler)
+
+	// Create a new request
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/users/test@example.com", nil)
+	require.NoError(t, err)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("User-Agent", "test")
+	router.ServeHTTP(resp, req)
+
+	// Check the panic is logged
+	require.Equal(t, 1, handler.Records())
+}
@@ -0,0 +1,29 @@
+package recovery
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// problemContentType is the RFC 7807 problem details content type.
+const problemContentType = "application/problem+json"
+
+// problem represents the RFC 7807 problem details of a panic.
+type problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// problemResponse responds with the RFC 7807 problem details of a panic.
+func problemResponse(c *gin.Context, requestID string, err any) {
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(http.StatusInternalServerError, problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(http.StatusInternalServerError),
+		Status:    http.StatusInternalServerError,
+		RequestID: requestID,
+	})
+}
@@ -0,0 +1,154 @@
+package recovery
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// maxStackFrames is the maximum number of frames captured when the stack is
+// not logged with debug.Stack.
+const maxStackFrames = 64
+
+// DefaultStackFilter are the function prefixes of the Go runtime, net/http,
+// gin and ginslog middlewares frames, see WithStackFilter.
+var DefaultStackFilter = []string{
+	"runtime.",
+	"net/http.",
+	"github.com/gin-gonic/gin.",
+	"github.com/FabienMht/ginslog/logger.NewFromConfig.",
+	"github.com/FabienMht/ginslog/recovery.New.",
+	"github.com/FabienMht/ginslog/requestid.New.",
+}
+
+// stackAttr returns the stack trace of the panic. It must be called by the
+// deferred function recovering the panic.
+func (c *Config) stackAttr(key string) slog.Attr {
+	if !c.structuredStack && len(c.stackFilter) == 0 && c.stackMaxFrames == 0 {
+		return slog.String(key, truncateStack(string(debug.Stack()), c.stackMaxBytes))
+	}
+
+	frames := limitFrames(filterFrames(panicFrames(), c.stackFilter), c.stackMaxFrames, c.stackMaxBytes)
+	if c.structuredStack {
+		return stackGroup(key, frames)
+	}
+	return slog.String(key, formatFrames(frames))
+}
+
+// originAttrs returns the file, the line and the function of the frame that
+// panicked, skipping the Go runtime frames. It must be called by the deferred
+// function recovering the panic.
+func originAttrs() []any {
+	frames := filterFrames(panicFrames(), []string{"runtime."})
+	if len(frames) == 0 {
+		return nil
+	}
+	return []any{
+		slog.String("file", frames[0].File),
+		slog.Int("line", frames[0].Line),
+		slog.String("func", frames[0].Function),
+	}
+}
+
+// panicFrames returns the stack frames of the goroutine starting at the frame
+// that panicked. It must be called by the deferred function recovering the
+// panic.
+func panicFrames() []runtime.Frame {
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(2, pcs)
+
+	frames := []runtime.Frame{}
+	iter := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+
+	// Skip the recovery frames up to the panic
+	for i, frame := range frames {
+		if frame.Function == "runtime.gopanic" {
+			return frames[i+1:]
+		}
+	}
+	return frames
+}
+
+// stackGroup returns a group with the function, the file and the line of
+// the frames.
+func stackGroup(key string, frames []runtime.Frame) slog.Attr {
+	attrs := make([]any, 0, len(frames))
+	for i, frame := range frames {
+		attrs = append(attrs, slog.Group(strconv.Itoa(i),
+			slog.String("function", frame.Function),
+			slog.String("file", frame.File),
+			slog.Int("line", frame.Line),
+		))
+	}
+	return slog.Group(key, attrs...)
+}
+
+// filterFrames returns the frames whose function doesn't start with one of
+// the prefixes.
+func filterFrames(frames []runtime.Frame, prefixes []string) []runtime.Frame {
+	filtered := make([]runtime.Frame, 0, len(frames))
+	for _, frame := range frames {
+		if !hasPrefix(frame.Function, prefixes) {
+			filtered = append(filtered, frame)
+		}
+	}
+	return filtered
+}
+
+// hasPrefix checks if s starts with one of the prefixes.
+func hasPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFrames formats the frames like a goroutine stack trace.
+func formatFrames(frames []runtime.Frame) string {
+	var b strings.Builder
+	for _, frame := range frames {
+		fmt.Fprintf(&b, "%s()\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return b.String()
+}
+
+// limitFrames returns the first frames up to maxFrames frames and maxBytes
+// bytes once formatted, zero for no limit.
+func limitFrames(frames []runtime.Frame, maxFrames, maxBytes int) []runtime.Frame {
+	if maxFrames > 0 && len(frames) > maxFrames {
+		frames = frames[:maxFrames]
+	}
+	if maxBytes > 0 {
+		size := 0
+		for i, frame := range frames {
+			size += len(formatFrames([]runtime.Frame{frame}))
+			if size > maxBytes {
+				return frames[:i]
+			}
+		}
+	}
+	return frames
+}
+
+// truncateStack truncates the stack trace to maxBytes bytes, zero for no limit.
+func truncateStack(stack string, maxBytes int) string {
+	if maxBytes <= 0 || len(stack) <= maxBytes {
+		return stack
+	}
+	return stack[:maxBytes] + stackTruncated
+}
+
+// stackTruncated is appended to the truncated stack traces.
+const stackTruncated = "\n[truncated]"
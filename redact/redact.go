@@ -0,0 +1,205 @@
+package redact
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the sensitive values.
+const Redacted = "[REDACTED]"
+
+// DefaultHeaders are the headers redacted by default.
+var DefaultHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+const (
+	// CreditCardPattern matches the credit card numbers.
+	CreditCardPattern = `\b(?:\d[ -]?){12,18}\d\b`
+	// EmailPattern matches the email addresses.
+	EmailPattern = `[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`
+)
+
+// Redactor represents a redaction policy applied to the logged headers
+// and requests. It is safe for concurrent use and can be shared between
+// the logging and the recovery middlewares.
+type Redactor struct {
+	// Regexes matching the sensitive headers names.
+	headers []*regexp.Regexp
+	// Regexes matching the sensitive query parameters names.
+	queryKeys []*regexp.Regexp
+	// Regexes matching the sensitive values in the paths and queries.
+	values []*regexp.Regexp
+}
+
+// Option allows to customize the redaction policy.
+type Option func(*Redactor)
+
+// New returns a new Redactor. By default, the DefaultHeaders are redacted.
+func New(opts ...Option) *Redactor {
+	r := &Redactor{
+		headers:   []*regexp.Regexp{},
+		queryKeys: []*regexp.Regexp{},
+		values:    []*regexp.Regexp{},
+	}
+	WithHeaders(DefaultHeaders)(r)
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithHeaders allows to redact the given headers, the names are matched case-insensitively.
+func WithHeaders(headers []string) Option {
+	return func(r *Redactor) {
+		for _, h := range headers {
+			r.headers = append(r.headers, regexp.MustCompile("(?i)^"+regexp.QuoteMeta(h)+"$"))
+		}
+	}
+}
+
+// WithHeaderPatterns allows to redact the headers whose names match the given
+// regexes (e.g. "(?i)^x-.*-token$"). It panics if a regex is invalid.
+func WithHeaderPatterns(patterns []string) Option {
+	return func(r *Redactor) {
+		for _, p := range patterns {
+			r.headers = append(r.headers, regexp.MustCompile(p))
+		}
+	}
+}
+
+// WithQueryKeys allows to redact the values of the query parameters whose
+// names match the given regexes (e.g. "(?i)^(password|token)$").
+// It panics if a regex is invalid.
+func WithQueryKeys(patterns []string) Option {
+	return func(r *Redactor) {
+		for _, p := range patterns {
+			r.queryKeys = append(r.queryKeys, regexp.MustCompile(p))
+		}
+	}
+}
+
+// WithValuePatterns allows to redact the parts of the paths and the query
+// parameters values matching the given regexes (e.g. CreditCardPattern,
+// EmailPattern). It panics if a regex is invalid.
+func WithValuePatterns(patterns []string) Option {
+	return func(r *Redactor) {
+		for _, p := range patterns {
+			r.values = append(r.values, regexp.MustCompile(p))
+		}
+	}
+}
+
+// WithoutDefaultHeaders to not redact the DefaultHeaders.
+// It must be used before the other options.
+func WithoutDefaultHeaders() Option {
+	return func(r *Redactor) {
+		r.headers = []*regexp.Regexp{}
+	}
+}
+
+// IsSensitiveHeader checks if a header value must be redacted.
+func (r *Redactor) IsSensitiveHeader(name string) bool {
+	for _, v := range r.headers {
+		if v.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Header returns a copy of the headers with the sensitive values redacted.
+func (r *Redactor) Header(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name, values := range redacted {
+		if r.IsSensitiveHeader(name) {
+			for i := range values {
+				values[i] = Redacted
+			}
+		}
+	}
+	return redacted
+}
+
+// String returns the string with the sensitive values redacted.
+func (r *Redactor) String(s string) string {
+	for _, v := range r.values {
+		s = v.ReplaceAllLiteralString(s, Redacted)
+	}
+	return s
+}
+
+// Path returns the URL path with the sensitive values redacted.
+func (r *Redactor) Path(path string) string {
+	return r.String(path)
+}
+
+// Query returns the raw query with the sensitive parameters values redacted.
+// The parameters order is preserved.
+func (r *Redactor) Query(query string) string {
+	if query == "" || (len(r.queryKeys) == 0 && len(r.values) == 0) {
+		return query
+	}
+
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+
+		if name, err := url.QueryUnescape(key); err == nil && r.isSensitiveQueryKey(name) {
+			params[i] = key + "=" + Redacted
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			if redacted := r.String(unescaped); redacted != unescaped {
+				// Escape the value again, keeping the marker readable
+				escaped := url.QueryEscape(redacted)
+				params[i] = key + "=" + strings.ReplaceAll(escaped, url.QueryEscape(Redacted), Redacted)
+			}
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// URL returns the raw URL (absolute or request URI) with the sensitive values
+// of the path and the query redacted.
+func (r *Redactor) URL(rawURL string) string {
+	path, query, ok := strings.Cut(rawURL, "?")
+	if !ok {
+		return r.Path(path)
+	}
+	return r.Path(path) + "?" + r.Query(query)
+}
+
+// Request returns a shallow copy of the request with the sensitive headers
+// values, path and query redacted, to dump it safely. The body is shared with
+// the original request.
+func (r *Redactor) Request(req *http.Request) *http.Request {
+	redacted := req.WithContext(req.Context())
+	redacted.Header = r.Header(req.Header)
+
+	requestURI := req.RequestURI
+	if requestURI == "" {
+		requestURI = req.URL.RequestURI()
+	}
+	redacted.RequestURI = r.URL(requestURI)
+	return redacted
+}
+
+// isSensitiveQueryKey checks if a query parameter value must be redacted.
+func (r *Redactor) isSensitiveQueryKey(name string) bool {
+	for _, v := range r.queryKeys {
+		if v.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
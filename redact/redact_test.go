@@ -0,0 +1,123 @@
+package redact
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSensitiveHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		header string
+		want   bool
+	}{
+		{
+			name:   "default header",
+			opts:   []Option{},
+			header: "Authorization",
+			want:   true,
+		},
+		{
+			name:   "default header lowercase",
+			opts:   []Option{},
+			header: "x-api-key",
+			want:   true,
+		},
+		{
+			name:   "not sensitive header",
+			opts:   []Option{},
+			header: "Accept",
+			want:   false,
+		},
+		{
+			name:   "with headers",
+			opts:   []Option{WithHeaders([]string{"X-Auth-Token"})},
+			header: "X-Auth-Token",
+			want:   true,
+		},
+		{
+			name:   "with header patterns",
+			opts:   []Option{WithHeaderPatterns([]string{"(?i)-token$"})},
+			header: "X-Session-Token",
+			want:   true,
+		},
+		{
+			name:   "without default headers",
+			opts:   []Option{WithoutDefaultHeaders()},
+			header: "Authorization",
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, New(tt.opts...).IsSensitiveHeader(tt.header))
+		})
+	}
+}
+
+func TestRequest(t *testing.T) {
+	req, err := http.NewRequest("GET", "/test?token=secret", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Add("Set-Cookie", "a=1")
+	req.Header.Add("Set-Cookie", "b=2")
+	req.Header.Set("Accept", "application/json")
+
+	redacted := New(WithQueryKeys([]string{"^token$"})).Request(req)
+	require.Equal(t, "/test?token="+Redacted, redacted.RequestURI)
+	require.Equal(t, []string{Redacted}, redacted.Header.Values("Authorization"))
+	require.Equal(t, []string{Redacted, Redacted}, redacted.Header.Values("Set-Cookie"))
+	require.Equal(t, "application/json", redacted.Header.Get("Accept"))
+
+	// The original request is not modified
+	require.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	require.Equal(t, "token=secret", req.URL.RawQuery)
+}
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		url  string
+		want string
+	}{
+		{
+			name: "default redactor",
+			opts: []Option{},
+			url:  "/users/john@example.com?token=secret",
+			want: "/users/john@example.com?token=secret",
+		},
+		{
+			name: "with query keys",
+			opts: []Option{WithQueryKeys([]string{"(?i)^(password|token)$"})},
+			url:  "/login?user=john&Token=secret&page=1&password",
+			want: "/login?user=john&Token=[REDACTED]&page=1&password",
+		},
+		{
+			name: "with email pattern",
+			opts: []Option{WithValuePatterns([]string{EmailPattern})},
+			url:  "/users/john@example.com?email=jane%40example.com&page=1",
+			want: "/users/[REDACTED]?email=[REDACTED]&page=1",
+		},
+		{
+			name: "with email pattern and encoded characters",
+			opts: []Option{WithValuePatterns([]string{EmailPattern})},
+			url:  "/users?q=a%26b%3Dc%20jane%40example.com&page=1",
+			want: "/users?q=a%26b%3Dc+[REDACTED]&page=1",
+		},
+		{
+			name: "with credit card pattern",
+			opts: []Option{WithValuePatterns([]string{CreditCardPattern})},
+			url:  "https://example.com/pay?card=4111+1111+1111+1111&amount=100",
+			want: "https://example.com/pay?card=[REDACTED]&amount=100",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, New(tt.opts...).URL(tt.url))
+		})
+	}
+}
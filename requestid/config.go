@@ -0,0 +1,130 @@
+package requestid
+
+// Generator allows to generate a new request ID.
+type Generator func() string
+
+// Config represents the request ID middleware configuration.
+type Config struct {
+	// HTTP header carrying the request ID.
+	header string
+
+	// Function to generate a new request ID.
+	generator Generator
+
+	// Reuse the request ID header sent by the client if valid.
+	trustHeader bool
+
+	// Function to validate the client request ID.
+	validator Validator
+
+	// Function to sanitize an invalid client request ID.
+	// Invalid request IDs are regenerated if nil.
+	sanitizer Sanitizer
+
+	// Set the request ID header in the response.
+	responseHeader bool
+}
+
+// newConfig returns a new Config.
+func newConfig() *Config {
+	return &Config{
+		header:         HeaderRequestID,
+		generator:      newUUIDv4,
+		trustHeader:    false,
+		validator:      ValidateSafe(maxRequestIDLength),
+		sanitizer:      nil,
+		responseHeader: true,
+	}
+}
+
+// clientRequestID returns the client request ID if valid, or its sanitized
+// version if a sanitizer is set. It returns an empty string if the request
+// ID must be regenerated.
+func (c *Config) clientRequestID(id string) string {
+	if c.validator(id) {
+		return id
+	}
+	if c.sanitizer != nil {
+		if id = c.sanitizer(id); c.validator(id) {
+			return id
+		}
+	}
+	return ""
+}
+
+// validate validates the Config.
+func (c *Config) validate() {
+	if c.header == "" {
+		panic("empty request ID header")
+	}
+	if c.generator == nil {
+		panic("no request ID generator")
+	}
+	if c.validator == nil {
+		panic("no request ID validator")
+	}
+}
+
+// ConfigOption allows to customize the middleware config.
+type ConfigOption func(*Config)
+
+// WithHeader allows to set the HTTP header carrying the request ID.
+// By default, the X-Request-ID header is used.
+func WithHeader(header string) ConfigOption {
+	return func(c *Config) {
+		c.header = header
+	}
+}
+
+// WithGenerator allows to set a custom function to generate the request ID.
+// By default, a random UUID (version 4) is generated.
+func WithGenerator(generator Generator) ConfigOption {
+	return func(c *Config) {
+		c.generator = generator
+	}
+}
+
+// WithUUIDv7 allows to generate time-ordered UUIDs (version 7) instead of
+// random UUIDs (version 4), so request IDs sort chronologically in log
+// stores and databases.
+func WithUUIDv7() ConfigOption {
+	return func(c *Config) {
+		c.generator = newUUIDv7
+	}
+}
+
+// WithTrustHeader allows to reuse the request ID header sent by the client
+// instead of generating a new one, so IDs correlate across services.
+// By default, the client value is only reused if it is not longer than 128
+// characters and contains only alphanumeric characters, '-', '_', '.' or ':'.
+// Otherwise, a new request ID is generated. See WithValidator and WithSanitizer.
+func WithTrustHeader() ConfigOption {
+	return func(c *Config) {
+		c.trustHeader = true
+	}
+}
+
+// WithValidator allows to set a custom function to validate the client request ID
+// when WithTrustHeader is used (e.g. ValidateUUID or ValidateSafe).
+// Invalid request IDs are regenerated.
+func WithValidator(validator Validator) ConfigOption {
+	return func(c *Config) {
+		c.validator = validator
+	}
+}
+
+// WithSanitizer allows to sanitize the invalid client request IDs instead of
+// regenerating them (e.g. SanitizeSafe). If the sanitized request ID is still
+// invalid, a new request ID is generated.
+func WithSanitizer(sanitizer Sanitizer) ConfigOption {
+	return func(c *Config) {
+		c.sanitizer = sanitizer
+	}
+}
+
+// WithoutResponseHeader to not set the request ID header in the response.
+func WithoutResponseHeader() ConfigOption {
+	return func(c *Config) {
+		c.responseHeader = false
+	}
+}
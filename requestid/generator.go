@@ -0,0 +1,31 @@
+package requestid
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newUUIDv4 generates a random UUID (version 4).
+func newUUIDv4() string {
+	return uuid.New().String()
+}
+
+// newUUIDv7 generates a time-ordered UUID (version 7) as defined in the RFC 9562.
+// The 48 most significant bits are the Unix timestamp in milliseconds,
+// the remaining bits are random except the version and variant bits.
+func newUUIDv7() string {
+	// Random UUID with the variant bits already set
+	id := uuid.New()
+
+	// Set the timestamp in the 6 first bytes (big-endian)
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(time.Now().UnixMilli()))
+	copy(id[0:6], timestamp[2:8])
+
+	// Set the version
+	id[6] = (id[6] & 0x0f) | 0x70
+
+	return id.String()
+}
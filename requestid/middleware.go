@@ -0,0 +1,76 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderRequestID is the default HTTP header carrying the request ID.
+const HeaderRequestID = "X-Request-ID"
+
+// requestIDKey is the key used to store the request ID in the gin context.
+const requestIDKey = "ginslog/request-id"
+
+// ContextKey is the key used to store the request ID in the request context.
+type ContextKey struct{}
+
+// headerKey is the key used to store the request ID header in the request
+// context, so the request ID is propagated in the same header.
+type headerKey struct{}
+
+// New returns a gin.HandlerFunc (middleware) that sets a request ID.
+//
+// By default, a random UUID is generated for each request, set in the
+// X-Request-ID response header and stored in the gin context and the
+// request context. It can be retrieved by the handlers and the other
+// middlewares with Get.
+//
+// The middleware should be mounted before the middlewares that use the
+// request ID (logging, authentication, tracing, ...).
+func New(opts ...ConfigOption) gin.HandlerFunc {
+	config := newConfig()
+	for _, opt := range opts {
+		opt(config)
+	}
+	config.validate()
+
+	return func(c *gin.Context) {
+		// Reuse the client request ID or generate a new one
+		var requestID string
+		if config.trustHeader {
+			requestID = config.clientRequestID(c.GetHeader(config.header))
+		}
+		if requestID == "" {
+			requestID = config.generator()
+		}
+
+		// Store the request ID in the gin context, the request ID and its header
+		// in the request context
+		c.Set(requestIDKey, requestID)
+		ctx := context.WithValue(c.Request.Context(), ContextKey{}, requestID)
+		c.Request = c.Request.WithContext(context.WithValue(ctx, headerKey{}, config.header))
+
+		if config.responseHeader {
+			c.Header(config.header, requestID)
+		}
+	}
+}
+
+// Get returns the request ID set by the middleware.
+// It returns an empty string if the middleware is not used.
+func Get(c *gin.Context) string {
+	if requestID := c.GetString(requestIDKey); requestID != "" {
+		return requestID
+	}
+	return FromContext(c.Request.Context())
+}
+
+// FromContext returns the request ID stored in the request context.
+// It returns an empty string if the middleware is not used.
+func FromContext(ctx context.Context) string {
+	if requestID, ok := ctx.Value(ContextKey{}).(string); ok {
+		return requestID
+	}
+	return ""
+}
@@ -0,0 +1,261 @@
+package requestid
+
+import (
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []ConfigOption
+		header     string
+		requestID  string
+		want       string
+		wantHeader string
+		wantPanic  bool
+	}{
+		{
+			name:       "default options",
+			opts:       []ConfigOption{},
+			header:     "X-Request-ID",
+			requestID:  "client-id",
+			want:       "52fdfc07-2182-454f-963f-5f0f9a621d72",
+			wantHeader: "52fdfc07-2182-454f-963f-5f0f9a621d72",
+		},
+		{
+			name:       "with header",
+			opts:       []ConfigOption{WithHeader("X-Trace-ID"), WithTrustHeader()},
+			header:     "X-Trace-ID",
+			requestID:  "client-id",
+			want:       "client-id",
+			wantHeader: "client-id",
+		},
+		{
+			name:       "with generator",
+			opts:       []ConfigOption{WithGenerator(func() string { return "generated-id" })},
+			header:     "X-Request-ID",
+			requestID:  "",
+			want:       "generated-id",
+			wantHeader: "generated-id",
+		},
+		{
+			name:       "with trust header",
+			opts:       []ConfigOption{WithTrustHeader()},
+			header:     "X-Request-ID",
+			requestID:  "client-id",
+			want:       "client-id",
+			wantHeader: "client-id",
+		},
+		{
+			name:       "with trust header invalid",
+			opts:       []ConfigOption{WithTrustHeader()},
+			header:     "X-Request-ID",
+			requestID:  "client id",
+			want:       "52fdfc07-2182-454f-963f-5f0f9a621d72",
+			wantHeader: "52fdfc07-2182-454f-963f-5f0f9a621d72",
+		},
+		{
+			name:       "with validator UUID",
+			opts:       []ConfigOption{WithTrustHeader(), WithValidator(ValidateUUID)},
+			header:     "X-Request-ID",
+			requestID:  "client-id",
+			want:       "52fdfc07-2182-454f-963f-5f0f9a621d72",
+			wantHeader: "52fdfc07-2182-454f-963f-5f0f9a621d72",
+		},
+		{
+			name:       "with validator UUID valid",
+			opts:       []ConfigOption{WithTrustHeader(), WithValidator(ValidateUUID)},
+			header:     "X-Request-ID",
+			requestID:  "9566c74d-1003-4c4d-bbbb-0407d1e2c649",
+			want:       "9566c74d-1003-4c4d-bbbb-0407d1e2c649",
+			wantHeader: "9566c74d-1003-4c4d-bbbb-0407d1e2c649",
+		},
+		{
+			name:       "with validator max length",
+			opts:       []ConfigOption{WithTrustHeader(), WithValidator(ValidateSafe(4))},
+			header:     "X-Request-ID",
+			requestID:  "client-id",
+			want:       "52fdfc07-2182-454f-963f-5f0f9a621d72",
+			wantHeader: "52fdfc07-2182-454f-963f-5f0f9a621d72",
+		},
+		{
+			name:       "with sanitizer",
+			opts:       []ConfigOption{WithTrustHeader(), WithSanitizer(SanitizeSafe(9))},
+			header:     "X-Request-ID",
+			requestID:  "client id\"=injected",
+			want:       "clientidi",
+			wantHeader: "clientidi",
+		},
+		{
+			name:       "with sanitizer still invalid",
+			opts:       []ConfigOption{WithTrustHeader(), WithSanitizer(SanitizeSafe(9))},
+			header:     "X-Request-ID",
+			requestID:  "\"=",
+			want:       "52fdfc07-2182-454f-963f-5f0f9a621d72",
+			wantHeader: "52fdfc07-2182-454f-963f-5f0f9a621d72",
+		},
+		{
+			name:       "without response header",
+			opts:       []ConfigOption{WithoutResponseHeader()},
+			header:     "X-Request-ID",
+			requestID:  "",
+			want:       "52fdfc07-2182-454f-963f-5f0f9a621d72",
+			wantHeader: "",
+		},
+		{
+			name:      "empty header",
+			opts:      []ConfigOption{WithHeader("")},
+			wantPanic: true,
+		},
+		{
+			name:      "nil generator",
+			opts:      []ConfigOption{WithGenerator(nil)},
+			wantPanic: true,
+		},
+		{
+			name:      "nil validator",
+			opts:      []ConfigOption{WithValidator(nil)},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Set a fixed random seed to get a fixed request ID
+			uuid.SetRand(rand.New(rand.NewSource(1)))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			if tt.wantPanic {
+				require.Panics(t, func() { router.Use(New(tt.opts...)) })
+				return
+			}
+			router.Use(New(tt.opts...))
+
+			// Define routes
+			router.GET("/test", func(c *gin.Context) {
+				require.Equal(t, tt.want, Get(c))
+				require.Equal(t, tt.want, c.Request.Context().Value(ContextKey{}))
+				c.JSON(200, nil)
+			})
+
+			// Create a new request
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/test", nil)
+			req.Header.Set(tt.header, tt.requestID)
+			require.NoError(t, err)
+			router.ServeHTTP(resp, req)
+
+			// Check the response header
+			require.Equal(t, tt.wantHeader, resp.Header().Get(tt.header))
+		})
+	}
+}
+
+func TestPropagate(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []ConfigOption
+		header string
+	}{
+		{
+			name:   "default header",
+			header: HeaderRequestID,
+		},
+		{
+			name:   "custom header",
+			opts:   []ConfigOption{WithHeader("X-Correlation-Id")},
+			header: "X-Correlation-Id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a test server returning the request ID header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(r.Header.Get(tt.header)))
+			}))
+			defer server.Close()
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(New(append(tt.opts, WithGenerator(func() string { return "generated-id" }))...))
+
+			// Define routes
+			router.GET("/propagate", func(c *gin.Context) {
+				req, err := http.NewRequestWithContext(c.Request.Context(), "GET", server.URL, nil)
+				require.NoError(t, err)
+				Propagate(req)
+				require.Equal(t, "generated-id", req.Header.Get(tt.header))
+				c.JSON(200, nil)
+			})
+			router.GET("/transport", func(c *gin.Context) {
+				req, err := http.NewRequestWithContext(c.Request.Context(), "GET", server.URL, nil)
+				require.NoError(t, err)
+				client := &http.Client{Transport: NewTransport(nil)}
+				resp, err := client.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				require.Equal(t, "generated-id", string(body))
+				require.Empty(t, req.Header.Get(tt.header))
+				c.JSON(200, nil)
+			})
+
+			// Create the requests
+			for _, path := range []string{"/propagate", "/transport"} {
+				resp := httptest.NewRecorder()
+				req, err := http.NewRequest("GET", path, nil)
+				require.NoError(t, err)
+				router.ServeHTTP(resp, req)
+				require.Equal(t, 200, resp.Code)
+			}
+		})
+	}
+}
+
+func TestNewUUIDv7(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(New(WithUUIDv7()))
+
+	// Define routes
+	var requestIDs []string
+	router.GET("/test", func(c *gin.Context) {
+		requestIDs = append(requestIDs, Get(c))
+		c.JSON(200, nil)
+	})
+
+	// Create the requests
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/test", nil)
+		require.NoError(t, err)
+		router.ServeHTTP(resp, req)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// Check the request IDs are UUIDv7 sorted chronologically
+	require.Len(t, requestIDs, 3)
+	require.IsIncreasing(t, requestIDs)
+	for _, requestID := range requestIDs {
+		id, err := uuid.Parse(requestID)
+		require.NoError(t, err)
+		require.Equal(t, uuid.Version(7), id.Version())
+		require.Equal(t, uuid.RFC4122, id.Variant())
+		var timestamp [8]byte
+		copy(timestamp[2:8], id[0:6])
+		ms := int64(binary.BigEndian.Uint64(timestamp[:]))
+		require.WithinDuration(t, start, time.UnixMilli(ms), time.Second)
+	}
+}
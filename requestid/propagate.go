@@ -0,0 +1,60 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+)
+
+// Propagate sets the request ID header of an outgoing request from the
+// request ID stored in its context. The header is the one used by the
+// middleware (X-Request-ID by default, see WithHeader). The header is not set
+// if the context has no request ID or if the header is already set.
+//
+// The outgoing request must be created with the incoming request context:
+//
+//	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", url, nil)
+func Propagate(req *http.Request) {
+	requestID := FromContext(req.Context())
+	header := headerFromContext(req.Context())
+	if requestID == "" || req.Header.Get(header) != "" {
+		return
+	}
+	req.Header.Set(header, requestID)
+}
+
+// headerFromContext returns the request ID header stored in the context.
+// It returns HeaderRequestID if the middleware is not used.
+func headerFromContext(ctx context.Context) string {
+	if header, ok := ctx.Value(headerKey{}).(string); ok {
+		return header
+	}
+	return HeaderRequestID
+}
+
+// transport is an http.RoundTripper propagating the request ID.
+type transport struct {
+	// Underlying round tripper.
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if FromContext(req.Context()) != "" && req.Header.Get(headerFromContext(req.Context())) == "" {
+		// A RoundTripper must not modify the request
+		req = req.Clone(req.Context())
+		Propagate(req)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// NewTransport returns an http.RoundTripper that propagates the request ID
+// stored in the outgoing request context in the request ID header, see
+// Propagate. If base is nil, http.DefaultTransport is used.
+//
+//	client := &http.Client{Transport: requestid.NewTransport(nil)}
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
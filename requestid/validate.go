@@ -0,0 +1,76 @@
+package requestid
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// maxRequestIDLength is the default maximum length of a client-supplied request ID.
+const maxRequestIDLength = 128
+
+// Validator allows to check if a client-supplied request ID can be reused.
+// Return true if the request ID is valid, false otherwise.
+type Validator func(id string) bool
+
+// Sanitizer allows to fix an invalid client-supplied request ID.
+// The sanitized request ID is validated again and a new request ID
+// is generated if it is still invalid.
+type Sanitizer func(id string) string
+
+// isSafeChar checks if a character is allowed in a request ID.
+func isSafeChar(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z':
+	case r >= 'A' && r <= 'Z':
+	case r >= '0' && r <= '9':
+	case r == '-' || r == '_' || r == '.' || r == ':':
+	default:
+		return false
+	}
+	return true
+}
+
+// ValidateSafe returns a Validator accepting request IDs not longer than maxLength
+// and containing only alphanumeric characters, '-', '_', '.' or ':'.
+func ValidateSafe(maxLength int) Validator {
+	return func(id string) bool {
+		if id == "" || len(id) > maxLength {
+			return false
+		}
+		for _, r := range id {
+			if !isSafeChar(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// ValidateUUID is a Validator accepting only UUIDs in their canonical form
+// (xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx).
+func ValidateUUID(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// SanitizeSafe returns a Sanitizer removing the characters other than
+// alphanumeric characters, '-', '_', '.' or ':' and truncating the
+// request ID to maxLength.
+func SanitizeSafe(maxLength int) Sanitizer {
+	return func(id string) string {
+		id = strings.Map(func(r rune) rune {
+			if isSafeChar(r) {
+				return r
+			}
+			return -1
+		}, id)
+		if len(id) > maxLength {
+			id = id[:maxLength]
+		}
+		return id
+	}
+}
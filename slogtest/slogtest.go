@@ -7,6 +7,7 @@ import (
 	"slices"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -22,6 +23,8 @@ type MockHandler struct {
 	fields []slog.Attr
 	// Ignore value check for these fields.
 	skipFields []string
+	// Number of records handled.
+	records int
 }
 
 // NewMockHandler creates a new mock handler.
@@ -29,19 +32,29 @@ func NewMockHandler(h slog.Handler, t *testing.T, l slog.Level, f []slog.Attr, s
 	return &MockHandler{Handler: h, testing: t, level: l, fields: f, skipFields: sf}
 }
 
+// Records returns the number of records handled.
+func (h *MockHandler) Records() int {
+	return h.records
+}
+
 // Handle implements Handler.Handle.
 func (h *MockHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.records++
+
 	// Check if the level matches.
 	require.Equal(h.testing, h.level, r.Level)
 
 	// Get fields names from fields to check.
-	fieldsMap := make(map[string]any)
+	fieldsMap := make(map[string]slog.Value)
 	for _, f := range h.fields {
 		fieldsMap[f.Key] = f.Value
 	}
 
 	// Check each field in the record.
+	found := 0
 	r.Attrs(func(a slog.Attr) bool {
+		found++
+
 		// Check if the field exists in the expected fields.
 		value, ok := fieldsMap[a.Key]
 		require.True(h.testing, ok, fmt.Sprintf("field '%s' not found", a.Key))
@@ -52,12 +65,43 @@ func (h *MockHandler) Handle(ctx context.Context, r slog.Record) error {
 		}
 
 		// Check if the field value matches with the expected value.
-		require.Equal(
-			h.testing, value, a.Value,
+		require.True(
+			h.testing, equalValues(value, a.Value),
 			fmt.Sprintf("field '%s' value '%s' does not match with '%s'", a.Key, a.Value, value),
 		)
 		return true
 	})
 
+	// Check if all the expected fields are in the record.
+	require.Equal(h.testing, len(h.fields), found, "number of fields does not match")
+
 	return h.Handler.Handle(ctx, r)
 }
+
+// equalValues checks if two slog values are equal.
+// Groups are compared recursively and any values are compared with their
+// underlying values.
+func equalValues(expected, actual slog.Value) bool {
+	if expected.Kind() != actual.Kind() {
+		return false
+	}
+
+	switch expected.Kind() {
+	case slog.KindGroup:
+		expectedAttrs, actualAttrs := expected.Group(), actual.Group()
+		if len(expectedAttrs) != len(actualAttrs) {
+			return false
+		}
+		for i := range expectedAttrs {
+			if expectedAttrs[i].Key != actualAttrs[i].Key ||
+				!equalValues(expectedAttrs[i].Value, actualAttrs[i].Value) {
+				return false
+			}
+		}
+		return true
+	case slog.KindAny, slog.KindLogValuer:
+		return assert.ObjectsAreEqual(expected.Any(), actual.Any())
+	default:
+		return expected.Equal(actual)
+	}
+}